@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+func addCommand() *command {
+	return &command{
+		name:  "add",
+		args:  "<feature>...",
+		short: "Add features to an existing project",
+		long: `Adds features to a project generated by ginit. New files are created and
+generated files the features change, such as internal/config/config.go, are
+updated. If any of them was modified since generation, it is reported as a
+conflict and nothing is written. Run 'ginit features' for the list.
+Features the policy of the settings file forbids are refused.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			dir := fs.String("dir", ".", "Project directory")
+			dryRun := fs.Bool("dry-run", false, "Only show what would change")
+			level := logFlags(fs)
+
+			return func(args []string) error {
+				if len(args) == 0 {
+					return usageErrorf("expected at least one feature name")
+				}
+
+				g, err := projectGenerator(level())
+				if err != nil {
+					return err
+				}
+				changes, err := g.AddFeatures(context.Background(), *dir, args, *dryRun)
+				var conflict *ginit.ConflictError
+				if errors.As(err, &conflict) {
+					printChangeList(changes)
+					return err
+				}
+				if err != nil {
+					return err
+				}
+
+				return printChanges(changes, *dryRun, "Features added.")
+			}
+		},
+		complete: func(args []string) []string {
+			return ginit.DefaultFeatures().Names()
+		},
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+// completeCommand is the hidden command the completion scripts call:
+// ginit __complete <words after "ginit"...>. The last word is the one being
+// completed and may be empty. It prints one candidate per line.
+const completeCommand = "__complete"
+
+// completeDirs is printed instead of candidates when the shell should
+// complete directory names itself
+const completeDirs = ":dir"
+
+// flagCompletions complete flag values by flag name, for every command
+var flagCompletions = map[string]func() []string{
+	"type":    templateNames,
+	"feature": func() []string { return ginit.DefaultFeatures().Names() },
+	"preset": func() []string {
+		presets, err := loadPresets()
+		if err != nil {
+			return nil
+		}
+		return presets.Names()
+	},
+	"output":   func() []string { return []string{"text", "json"} },
+	"dir":      func() []string { return []string{completeDirs} },
+	"template": func() []string { return []string{completeDirs} },
+}
+
+func runComplete(words []string) int {
+	for _, candidate := range completions(words) {
+		fmt.Println(candidate)
+	}
+	return exitOK
+}
+
+// completions returns the candidates for the last of words
+func completions(words []string) []string {
+	cur := ""
+	if len(words) > 0 {
+		cur = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var cmd *command
+	var positional []string
+	if len(words) > 0 {
+		switch name := words[0]; {
+		case name == "help":
+			return filterPrefix(helpCompletions(words[1:]), cur)
+		case findCommand(name) != nil:
+			cmd = findCommand(name)
+			words = words[1:]
+		case strings.HasPrefix(name, "-"):
+			cmd = findCommand("new")
+		default:
+			return nil
+		}
+	}
+
+	if cmd == nil {
+		if strings.HasPrefix(cur, "-") {
+			return filterPrefix(flagNames(commandFlags(findCommand("new"))), cur)
+		}
+		return filterPrefix(append(commandNames(commands()), "help"), cur)
+	}
+
+	fs := commandFlags(cmd)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if word == "--" {
+			positional = append(positional, words[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(word, "-") || word == "-" {
+			if sub := cmd.findSubcommand([]string{word}); sub != nil && len(positional) == 0 {
+				cmd, fs = sub, commandFlags(sub)
+				continue
+			}
+			positional = append(positional, word)
+			continue
+		}
+		// Значение флага без "=" идет следующим словом
+		if f := lookupFlag(fs, word); f != nil && !isBoolFlag(f) && !strings.Contains(word, "=") {
+			if i == len(words)-1 {
+				return flagValues(f.Name, "", cur)
+			}
+			i++
+		}
+	}
+
+	if strings.HasPrefix(cur, "-") {
+		if name, value, ok := strings.Cut(cur, "="); ok {
+			if f := lookupFlag(fs, name); f != nil {
+				return flagValues(f.Name, name+"=", value)
+			}
+			return nil
+		}
+		return filterPrefix(flagNames(fs), cur)
+	}
+
+	var candidates []string
+	if len(positional) == 0 {
+		candidates = commandNames(cmd.subcommands)
+	}
+	if cmd.complete != nil {
+		values := cmd.complete(positional)
+		if len(values) == 1 && values[0] == completeDirs {
+			return values
+		}
+		candidates = append(candidates, values...)
+	}
+	return filterPrefix(candidates, cur)
+}
+
+// helpCompletions completes the command names after "ginit help"
+func helpCompletions(args []string) []string {
+	if len(args) == 0 {
+		return commandNames(commands())
+	}
+
+	cmd := findCommand(args[0])
+	for _, name := range args[1:] {
+		if cmd == nil {
+			return nil
+		}
+		cmd = cmd.findSubcommand([]string{name})
+	}
+	if cmd == nil {
+		return nil
+	}
+	return commandNames(cmd.subcommands)
+}
+
+func flagValues(name, prefix, cur string) []string {
+	complete, ok := flagCompletions[name]
+	if !ok {
+		return nil
+	}
+
+	values := complete()
+	if len(values) == 1 && values[0] == completeDirs {
+		return values
+	}
+
+	// Списочные флаги: -feature docker,ci
+	if i := strings.LastIndex(cur, ","); i >= 0 {
+		prefix, cur = prefix+cur[:i+1], cur[i+1:]
+	}
+
+	var candidates []string
+	for _, v := range filterPrefix(values, cur) {
+		candidates = append(candidates, prefix+v)
+	}
+	return candidates
+}
+
+// commandFlags returns the flag set of cmd without running it
+func commandFlags(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet("ginit "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cmd.setup(fs)
+	return fs
+}
+
+// lookupFlag finds the flag named by word, which may be written as -name,
+// --name or -name=value
+func lookupFlag(fs *flag.FlagSet, word string) *flag.Flag {
+	name := strings.TrimLeft(word, "-")
+	name, _, _ = strings.Cut(name, "=")
+	return fs.Lookup(name)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func flagNames(fs *flag.FlagSet) []string {
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, "-"+f.Name)
+	})
+	return names
+}
+
+// commandNames returns the last word of each command name
+func commandNames(cmds []*command) []string {
+	var names []string
+	for _, cmd := range cmds {
+		names = append(names, cmd.name[strings.LastIndex(cmd.name, " ")+1:])
+	}
+	return names
+}
+
+func filterPrefix(candidates []string, prefix string) []string {
+	var matched []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+// completeDirArg completes an optional directory argument
+func completeDirArg(args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	return []string{completeDirs}
+}
+
+// templateNames returns the names of the built-in types and the installed
+// templates without registering the packs, which would report their errors
+func templateNames() []string {
+	ix, err := loadTemplateIndex()
+	if err != nil {
+		return ginit.DefaultTypes().Names()
+	}
+	var names []string
+	for _, t := range ix.List() {
+		if t.Error == "" && !slices.Contains(names, t.Name) {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
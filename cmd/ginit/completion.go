@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+func completionCommand() *command {
+	return &command{
+		name:  "completion",
+		args:  "<bash|zsh|fish>",
+		short: "Print a shell completion script",
+		long: `Prints a completion script for the given shell. The script completes
+commands, flags, project types and template names by asking ginit itself, so
+it stays up to date when ginit is upgraded.
+
+  bash:  source <(ginit completion bash)          # in ~/.bashrc
+  zsh:   source <(ginit completion zsh)           # in ~/.zshrc, after compinit
+  fish:  ginit completion fish > ~/.config/fish/completions/ginit.fish`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) != 1 {
+					return usageErrorf("expected exactly one shell")
+				}
+
+				script, ok := completionScripts[args[0]]
+				if !ok {
+					return usageErrorf("unsupported shell %q: expected bash, zsh or fish", args[0])
+				}
+				fmt.Print(script)
+				return nil
+			}
+		},
+		complete: func(args []string) []string {
+			if len(args) > 0 {
+				return nil
+			}
+			return []string{"bash", "zsh", "fish"}
+		},
+	}
+}
+
+// The scripts pass the words typed so far to 'ginit __complete' and turn
+// its output into shell candidates; ":dir" asks for directory completion.
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+const bashCompletion = `# bash completion for ginit
+
+_ginit() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local -a words
+    read -ra words <<< "$line"
+    [[ "$line" == *[[:space:]] ]] && words+=("")
+
+    local cur="${words[${#words[@]}-1]}"
+    local IFS=$'\n'
+    local -a candidates
+    candidates=($(ginit __complete "${words[@]:1}" 2>/dev/null))
+
+    if [[ "${candidates[0]}" == ":dir" ]]; then
+        COMPREPLY=($(compgen -d -- "${cur#*=}"))
+        return
+    fi
+
+    # bash splits -type=web at "=", so only the value is replaced
+    if [[ "$cur" == *=* && "$COMP_WORDBREAKS" == *=* ]]; then
+        candidates=("${candidates[@]#*=}")
+    fi
+    COMPREPLY=("${candidates[@]}")
+}
+
+complete -F _ginit ginit
+`
+
+const zshCompletion = `#compdef ginit
+# zsh completion for ginit
+
+_ginit() {
+    local -a candidates
+    candidates=("${(@f)$(ginit __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    candidates=(${candidates:#})
+
+    if [[ "${candidates[1]}" == ":dir" ]]; then
+        _files -/
+        return
+    fi
+    compadd -- "${candidates[@]}"
+}
+
+compdef _ginit ginit
+`
+
+const fishCompletion = `# fish completion for ginit
+
+function __ginit_complete
+    set -l args (commandline -opc)[2..-1] (commandline -ct)
+    set -l candidates (ginit __complete $args 2>/dev/null)
+
+    if test "$candidates[1]" = ":dir"
+        __fish_complete_directories (commandline -ct)
+        return
+    end
+    printf '%s\n' $candidates
+end
+
+complete -c ginit -f -a '(__ginit_complete)'
+`
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/version"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+type checkResult struct {
+	name   string
+	status checkStatus
+	detail string
+	// hint tells the user how to fix a warning or failure
+	hint string
+}
+
+// doctorEnv is what the checks learn about the environment. Checks that
+// depend on the go tool are skipped when it is missing.
+type doctorEnv struct {
+	goPath  string
+	goEnv   map[string]string
+	gitPath string
+}
+
+type check func(env *doctorEnv) []checkResult
+
+func doctorCommand() *command {
+	return &command{
+		name:  "doctor",
+		short: "Check the environment ginit depends on",
+		long: `Checks the tools and settings ginit relies on while generating projects
+and prints how to fix any problem found: go and git in PATH, the Go version,
+module proxy settings, the module cache, the Git identity, terminal support
+for the interactive mode, the templates, the installed template packs and the
+tools verifying their signatures. Exits with status 1 if a check fails.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usageErrorf("unexpected arguments")
+				}
+
+				env := &doctorEnv{}
+				failed := false
+				for _, check := range doctorChecks() {
+					for _, r := range check(env) {
+						printCheck(r)
+						failed = failed || r.status == checkFail
+					}
+				}
+
+				if failed {
+					return silentError
+				}
+				return nil
+			}
+		},
+	}
+}
+
+func doctorChecks() []check {
+	return []check{
+		checkGo,
+		checkGoVersion,
+		checkGoProxy,
+		checkModuleCache,
+		checkGit,
+		checkGitIdentity,
+		checkTerminal,
+		checkTemplates,
+		checkInstalledTemplates,
+		checkSignatureTools,
+	}
+}
+
+func printCheck(r checkResult) {
+	style := tui.DefaultStyle()
+
+	var icon string
+	switch r.status {
+	case checkPass:
+		icon = tui.SelectedStyle.Render("✓")
+	case checkWarn:
+		icon = tui.WarningStyle.Render("!")
+	case checkFail:
+		icon = tui.StepFailedStyle.Render("✗")
+	}
+
+	fmt.Println(icon + " " + style.Value.Render(r.name) + style.Label.Render(": "+r.detail))
+	if r.hint != "" && r.status != checkPass {
+		fmt.Println(style.Label.Render("    → ") + style.Tip.Render(r.hint))
+	}
+}
+
+func pass(name, detail string) []checkResult {
+	return []checkResult{{name: name, status: checkPass, detail: detail}}
+}
+
+func warn(name, detail, hint string) []checkResult {
+	return []checkResult{{name: name, status: checkWarn, detail: detail, hint: hint}}
+}
+
+func fail(name, detail, hint string) []checkResult {
+	return []checkResult{{name: name, status: checkFail, detail: detail, hint: hint}}
+}
+
+func checkGo(env *doctorEnv) []checkResult {
+	path, err := exec.LookPath("go")
+	if err != nil {
+		return fail("go", "not found in PATH",
+			"install Go from https://go.dev/dl/ and add it to PATH; without it go.mod is written by hand and dependencies are skipped")
+	}
+
+	out, err := exec.Command(path, "env", "-json", "GOVERSION", "GOPROXY", "GOPRIVATE", "GOFLAGS", "GOMODCACHE").Output()
+	if err != nil {
+		return fail("go", "'go env' failed: "+err.Error(), "check that the Go installation at "+path+" is complete")
+	}
+	if err := json.Unmarshal(out, &env.goEnv); err != nil {
+		return fail("go", "cannot parse 'go env' output: "+err.Error(), "")
+	}
+
+	env.goPath = path
+	return pass("go", path)
+}
+
+func checkGoVersion(env *doctorEnv) []checkResult {
+	if env.goPath == "" {
+		return nil
+	}
+
+	goVersion := env.goEnv["GOVERSION"]
+	if !version.IsValid(goVersion) {
+		return warn("go version", "cannot determine the version from "+quoteVersion(goVersion),
+			"templates require Go "+ginit.MinGoVersion+" or newer")
+	}
+	if version.Compare(goVersion, "go"+ginit.MinGoVersion) < 0 {
+		return fail("go version", goVersion+" is older than go"+ginit.MinGoVersion,
+			"generated projects use log/slog; upgrade Go to "+ginit.MinGoVersion+" or newer")
+	}
+	return pass("go version", goVersion+" (templates require go"+ginit.MinGoVersion+"+)")
+}
+
+func checkGoProxy(env *doctorEnv) []checkResult {
+	if env.goPath == "" {
+		return nil
+	}
+
+	var results []checkResult
+
+	proxy := env.goEnv["GOPROXY"]
+	switch {
+	case proxy == "off":
+		results = append(results, warn("GOPROXY", "off",
+			"dependencies cannot be downloaded; set GOPROXY=https://proxy.golang.org,direct or your company proxy")...)
+	case proxy == "":
+		results = append(results, warn("GOPROXY", "empty, the go command will fall back to direct downloads",
+			"set GOPROXY explicitly with 'go env -w GOPROXY=...'")...)
+	default:
+		results = append(results, pass("GOPROXY", proxy)...)
+	}
+
+	private := env.goEnv["GOPRIVATE"]
+	if private == "" {
+		private = "not set"
+	}
+	results = append(results, pass("GOPRIVATE", private)...)
+
+	flags := env.goEnv["GOFLAGS"]
+	switch {
+	case strings.Contains(flags, "-mod=vendor"):
+		results = append(results, warn("GOFLAGS", flags,
+			"-mod=vendor makes 'go get' fail in new projects; remove it with 'go env -u GOFLAGS'")...)
+	case flags == "":
+		results = append(results, pass("GOFLAGS", "not set")...)
+	default:
+		results = append(results, pass("GOFLAGS", flags)...)
+	}
+
+	return results
+}
+
+func checkModuleCache(env *doctorEnv) []checkResult {
+	if env.goPath == "" {
+		return nil
+	}
+
+	dir := env.goEnv["GOMODCACHE"]
+	if dir == "" {
+		return warn("module cache", "GOMODCACHE is empty", "check your GOPATH settings")
+	}
+
+	// Кэш создается go при первой загрузке, поэтому проверяем ближайшую
+	// существующую директорию
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	f, err := os.CreateTemp(existing, ".ginit-doctor-*")
+	if err != nil {
+		return fail("module cache", dir+" is not writable: "+err.Error(),
+			"fix the permissions of "+existing+" or point GOMODCACHE to a writable directory")
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	return pass("module cache", dir+" is writable")
+}
+
+func checkGit(env *doctorEnv) []checkResult {
+	path, err := exec.LookPath("git")
+	if err != nil {
+		return warn("git", "not found in PATH", "install Git or generate projects with -no-vcs")
+	}
+
+	env.gitPath = path
+	return pass("git", path)
+}
+
+func checkGitIdentity(env *doctorEnv) []checkResult {
+	if env.gitPath == "" {
+		return nil
+	}
+
+	var missing []string
+	var values []string
+	for _, key := range []string{"user.name", "user.email"} {
+		out, err := exec.Command(env.gitPath, "config", "--get", key).Output()
+		value := strings.TrimSpace(string(out))
+		if err != nil || value == "" {
+			missing = append(missing, key)
+			continue
+		}
+		values = append(values, value)
+	}
+
+	if len(missing) > 0 {
+		return warn("git identity", strings.Join(missing, " and ")+" not set",
+			"the initial commit will fail; run 'git config --global user.name \"Your Name\"' and 'git config --global user.email you@example.com'")
+	}
+	return pass("git identity", strings.Join(values, " "))
+}
+
+func checkTerminal(env *doctorEnv) []checkResult {
+	if !term.IsTerminal(os.Stdout.Fd()) || !term.IsTerminal(os.Stdin.Fd()) {
+		return warn("terminal", "stdin/stdout is not a terminal",
+			"the interactive mode needs a terminal; use 'ginit new <name>' in scripts and CI")
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return warn("terminal", "TERM=dumb", "the interactive mode needs cursor movement; set TERM, e.g. TERM=xterm-256color")
+	}
+
+	width, height, err := term.GetSize(os.Stdout.Fd())
+	if err == nil && (width < 60 || height < 20) {
+		return warn("terminal", fmt.Sprintf("%dx%d is small for the interactive mode", width, height),
+			"resize the window to at least 60x20 to see the generation log")
+	}
+
+	profile := lipgloss.ColorProfile()
+	if profile == termenv.Ascii {
+		return warn("terminal", "no color support detected", "set COLORTERM=truecolor or TERM=xterm-256color if your terminal supports colors")
+	}
+	return pass("terminal", fmt.Sprintf("%dx%d, %s colors", width, height, profile.Name()))
+}
+
+func checkTemplates(env *doctorEnv) []checkResult {
+	registry := ginit.DefaultRegistry()
+	names := registry.Names()
+
+	var broken []string
+	for _, name := range names {
+		source, _ := registry.Lookup(name)
+		if _, err := template.New(name).Funcs(ginit.FuncMap()).Parse(source); err != nil {
+			broken = append(broken, err.Error())
+		}
+	}
+
+	if len(broken) > 0 {
+		return fail("templates", strings.Join(broken, "; "), "reinstall ginit")
+	}
+	return pass("templates", fmt.Sprintf("%d built-in templates parse", len(names)))
+}
+
+func checkInstalledTemplates(env *doctorEnv) []checkResult {
+	ix, err := loadTemplateIndex()
+	if err != nil {
+		return fail("installed templates", err.Error(), "fix the settings file or the template cache")
+	}
+	_ = ix.Register(ginit.DefaultRegistry(), ginit.DefaultTypes())
+
+	var results []checkResult
+	installed := 0
+	for _, t := range ix.List() {
+		switch {
+		case t.Kind == ginit.KindBuiltin:
+			continue
+		case t.Error != "":
+			msg, _, _ := strings.Cut(t.Error, "\n")
+			hint := "see 'ginit templates show " + t.Name + "'"
+			if t.Kind == ginit.KindCache {
+				hint = "reinstall it with 'ginit templates update " + t.Name + "'"
+			}
+			results = append(results, fail("template "+t.Name, msg, hint)...)
+		default:
+			installed++
+		}
+	}
+	return append(pass("installed templates", fmt.Sprintf("%d usable template packs", installed)), results...)
+}
+
+func checkSignatureTools(env *doctorEnv) []checkResult {
+	settings, err := loadSettings()
+	if err != nil {
+		return nil
+	}
+
+	var results []checkResult
+	for _, tool := range []struct{ keyType, command string }{
+		{ginit.KeySSH, "ssh-keygen"},
+		{ginit.KeyMinisign, "minisign"},
+	} {
+		if !slices.ContainsFunc(settings.TrustedKeys, func(k ginit.TrustedKey) bool { return k.Type == tool.keyType }) {
+			continue
+		}
+		path, err := exec.LookPath(tool.command)
+		if err != nil {
+			results = append(results, fail(tool.command, "not found in PATH",
+				"install "+tool.command+" to verify template packs signed with the trusted "+tool.keyType+" keys")...)
+			continue
+		}
+		results = append(results, pass(tool.command, path)...)
+	}
+	return results
+}
+
+func quoteVersion(s string) string {
+	if s == "" {
+		return "empty GOVERSION"
+	}
+	return fmt.Sprintf("%q", s)
+}
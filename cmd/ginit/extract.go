@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+func templatesExtractCommand() *command {
+	return &command{
+		name:  "templates extract",
+		args:  "<project-dir>",
+		short: "Turn an existing project into a template pack",
+		long: `Writes a template pack generating projects like the one in project-dir. The
+module path from go.mod, the project name and the identifiers derived from it
+(MyApp, myApp, my_app, my-app and MY_APP for my-app) are replaced by template
+expressions in every file and path; import paths of other modules are kept.
+The project name is read from .ginit.json if present, else it is the last
+element of the module path.
+
+Binary files and .git are skipped. The pack is checked with 'templates lint'
+after writing; review it before use, since a project name that is a common
+word may be replaced where it should not be.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			out := fs.String("out", "", "Directory to write the pack to (default: <name>-template)")
+			name := fs.String("name", "", "Name of the pack's project type (default: the project name)")
+
+			return func(args []string) error {
+				if len(args) != 1 {
+					return usageErrorf("expected exactly one project directory")
+				}
+
+				pack, skipped, err := ginit.ExtractPack(args[0])
+				if err != nil {
+					return err
+				}
+				if *name != "" {
+					pack.Manifest.Name = *name
+				}
+				if *out == "" {
+					*out = pack.Manifest.Name + "-template"
+				}
+				if err := pack.Write(*out); err != nil {
+					return err
+				}
+
+				style := tui.DefaultStyle()
+				fmt.Println(style.Label.Render("Files: ") + style.Value.Render(fmt.Sprint(len(pack.Files))))
+				for _, s := range skipped {
+					fmt.Println(style.Label.Render("Skipped: ") + style.Value.Render(s))
+				}
+
+				issues, err := ginit.LintPack(*out)
+				if err != nil {
+					return err
+				}
+				for _, issue := range issues {
+					fmt.Println(issue)
+				}
+
+				fmt.Println("")
+				fmt.Println(style.SuccessText.Render("Template pack written to " + *out))
+				fmt.Println(style.Label.Render("Create a project from it with ") +
+					style.Code.Render(fmt.Sprintf("ginit new <name> -template %s", *out)))
+				return nil
+			}
+		},
+		complete: completeDirArg,
+	}
+}
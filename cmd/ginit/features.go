@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+func featuresCommand() *command {
+	return &command{
+		name:  "features",
+		short: "List available features",
+		long: `Lists the features that can be added to a project with 'ginit new -feature'
+or 'ginit add', with the project types they support and their relations to
+other features.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usageErrorf("unexpected arguments")
+				}
+
+				style := tui.DefaultStyle()
+				for _, f := range ginit.DefaultFeatures().List() {
+					fmt.Println(style.Value.Render(fmt.Sprintf("%-12s", f.Name)) + style.Label.Render(f.Description))
+
+					var notes []string
+					if len(f.Types) > 0 {
+						notes = append(notes, "types: "+strings.Join(f.Types, ", "))
+					}
+					if len(f.Requires) > 0 {
+						notes = append(notes, "requires: "+strings.Join(f.Requires, ", "))
+					}
+					if len(f.Conflicts) > 0 {
+						notes = append(notes, "conflicts: "+strings.Join(f.Conflicts, ", "))
+					}
+					if len(notes) > 0 {
+						fmt.Println(style.Label.Render("            " + strings.Join(notes, "; ")))
+					}
+				}
+				return nil
+			}
+		},
+	}
+}
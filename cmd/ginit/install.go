@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+func templatesInstallCommand() *command {
+	return &command{
+		name:  "templates install",
+		args:  "<git-url|dir>",
+		short: "Install a template pack into the template cache",
+		long: `Installs the template pack at a git URL or in a local directory into the
+template cache (` + "$GINIT_CACHE" + ` or ginit/templates in the user cache directory).
+A git repository is cloned with git, which must be on PATH; -ref selects a
+branch or tag. The pack is then available as 'ginit new -type <name>'.
+
+The checksums of the installed files are recorded, and a pack changed in the
+cache afterwards is not used. If it contains ` + ginit.PackSumFile + `, the files must
+match it. With trusted_keys in the settings file, the pack must also carry a
+signature of ` + ginit.PackSumFile + ` by one of the keys (see 'ginit templates checksum'):
+
+  {
+    "trusted_keys": [
+      {"name": "platform-team", "type": "ssh", "key": "ssh-ed25519 AAAA..."},
+      {"name": "releases", "type": "minisign", "key": "RWQ..."}
+    ]
+  }`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			ref := fs.String("ref", "", "Branch or tag of a git repository")
+
+			return func(args []string) error {
+				if len(args) != 1 {
+					return usageErrorf("expected exactly one git URL or directory")
+				}
+
+				ix, err := loadTemplateIndex()
+				if err != nil {
+					return err
+				}
+				t, err := ix.Install(context.Background(), args[0], *ref)
+				if err != nil {
+					return commandOutput(err)
+				}
+
+				style := tui.DefaultStyle()
+				fmt.Println(style.SuccessText.Render("Installed " + templateVersion(t)))
+				if t.SignedBy != "" {
+					fmt.Println(style.Label.Render("Signed by ") + style.Value.Render(t.SignedBy))
+				}
+				fmt.Println(style.Label.Render("Create a project from it with ") +
+					style.Code.Render(fmt.Sprintf("ginit new <name> -type %s", t.Name)))
+				return nil
+			}
+		},
+		complete: completeDirArg,
+	}
+}
+
+func templatesUpdateCommand() *command {
+	return &command{
+		name:  "templates update",
+		args:  "[name...]",
+		short: "Update installed template packs",
+		long: `Fetches the named template packs, or all packs in the template cache, again
+from where they were installed from.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				ix, err := loadTemplateIndex()
+				if err != nil {
+					return err
+				}
+				names := args
+				if len(names) == 0 {
+					names = ix.Cached()
+				}
+
+				style := tui.DefaultStyle()
+				failed := 0
+				for _, name := range names {
+					old, updated, err := ix.Update(context.Background(), name)
+					if err != nil {
+						fmt.Println(tui.StepFailedStyle.Render("✗ ") + commandOutput(err).Error())
+						failed++
+						continue
+					}
+					switch {
+					case old.Error != "":
+						fmt.Println(tui.SelectedStyle.Render("✓ ") + style.Value.Render(templateVersion(updated)+" reinstalled"))
+						continue
+					case old.Checksum == updated.Checksum:
+						fmt.Println(style.Label.Render("  " + templateVersion(updated) + " is up to date"))
+						continue
+					}
+					fmt.Println(tui.SelectedStyle.Render("✓ ") + style.Value.Render(templateVersion(old)+" → "+templateVersion(updated)))
+				}
+				if failed > 0 {
+					return fmt.Errorf("%d of %d templates failed to update", failed, len(names))
+				}
+				return nil
+			}
+		},
+		complete: completeCachedTemplates,
+	}
+}
+
+func templatesRemoveCommand() *command {
+	return &command{
+		name:  "templates remove",
+		args:  "<name...>",
+		short: "Remove installed template packs",
+		long:  `Removes template packs from the template cache.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) == 0 {
+					return usageErrorf("expected at least one template name")
+				}
+
+				ix, err := loadTemplateIndex()
+				if err != nil {
+					return err
+				}
+				style := tui.DefaultStyle()
+				for _, name := range args {
+					if err := ix.Remove(name); err != nil {
+						return err
+					}
+					fmt.Println(style.SuccessText.Render("Removed " + name))
+				}
+				return nil
+			}
+		},
+		complete: completeCachedTemplates,
+	}
+}
+
+func templatesChecksumCommand() *command {
+	return &command{
+		name:  "templates checksum",
+		args:  "<dir>",
+		short: "Write the checksums of a template pack for signing",
+		long: `Writes ` + ginit.PackSumFile + ` with the SHA-256 checksums of the files of the template
+pack in dir. 'ginit templates install' refuses a pack whose files do not match
+it. Sign the file to let installations with trusted keys accept the pack:
+
+  ssh-keygen -Y sign -f ~/.ssh/id_ed25519 -n ` + ginit.SSHNamespace + ` ` + ginit.PackSumFile + `
+  minisign -S -m ` + ginit.PackSumFile + `
+
+The signatures are written to ` + ginit.PackSumFile + ginit.SSHSignatureSuffix + ` and ` + ginit.PackSumFile + ginit.MinisignSuffix + `. Run the
+command again and sign anew after changing the pack.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) != 1 {
+					return usageErrorf("expected exactly one template pack directory")
+				}
+				if _, err := ginit.LoadPack(args[0]); err != nil {
+					return err
+				}
+				if err := ginit.WriteChecksums(args[0]); err != nil {
+					return err
+				}
+
+				style := tui.DefaultStyle()
+				file := filepath.Join(args[0], ginit.PackSumFile)
+				fmt.Println(style.SuccessText.Render("Checksums written to " + file))
+				fmt.Println(style.Label.Render("Sign them with ") +
+					style.Code.Render(fmt.Sprintf("ssh-keygen -Y sign -f <key> -n %s %s", ginit.SSHNamespace, file)))
+				return nil
+			}
+		},
+		complete: completeDirArg,
+	}
+}
+
+// templateVersion names t with its version and, for a git origin, the
+// abbreviated commit, e.g. company-web 1.2.0 (3f26bf5)
+func templateVersion(t ginit.InstalledTemplate) string {
+	s := t.Name
+	if t.Version != "" {
+		s += " " + t.Version
+	}
+	if len(t.Commit) >= 7 {
+		s += " (" + t.Commit[:7] + ")"
+	}
+	return s
+}
+
+// commandOutput appends the output of a failed git command to err
+func commandOutput(err error) error {
+	var cmdErr *ginit.CommandError
+	if errors.As(err, &cmdErr) && len(cmdErr.Output) > 0 {
+		return fmt.Errorf("%w\n%s", err, strings.Join(cmdErr.Output, "\n"))
+	}
+	return err
+}
+
+func completeCachedTemplates(args []string) []string {
+	ix, err := loadTemplateIndex()
+	if err != nil {
+		return nil
+	}
+	return ix.Cached()
+}
@@ -1,158 +1,434 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"sort"
+	"strings"
 
-	"github.com/cardinalnsk/ginit/internal/generator"
 	"github.com/cardinalnsk/ginit/internal/tui"
-	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
 )
 
+// Exit codes
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// runFunc runs a command with its positional arguments
+type runFunc func(args []string) error
+
+type command struct {
+	name string
+	// args describes the positional arguments in the usage line
+	args  string
+	short string
+	long  string
+	// setup registers the command flags and returns the function running it
+	setup func(fs *flag.FlagSet) runFunc
+	// subcommands are selected by the first argument, e.g. templates show;
+	// their names include the parent name
+	subcommands []*command
+	// complete returns shell completion candidates for the next positional
+	// argument, given the positional arguments before it
+	complete func(args []string) []string
+}
+
+func commands() []*command {
+	return []*command{
+		newCommand(),
+		addCommand(),
+		typesCommand(),
+		featuresCommand(),
+		presetsCommand(),
+		policyCommand(),
+		templatesCommand(),
+		doctorCommand(),
+		upgradeCommand(),
+		statusCommand(),
+		completionCommand(),
+	}
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// findSubcommand returns the subcommand selected by the first argument
+func (c *command) findSubcommand(args []string) *command {
+	if len(args) == 0 {
+		return nil
+	}
+	for _, sub := range c.subcommands {
+		if sub.name == c.name+" "+args[0] {
+			return sub
+		}
+	}
+	return nil
+}
+
+// usageError reports invalid arguments; it makes ginit print the command
+// usage and exit with exitUsage
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// silentError makes ginit exit with exitFailure without printing anything,
+// for commands that already reported the failure themselves
+var silentError = errors.New("silent failure")
+
 func main() {
-	// Флаги для non-interactive режима
-	name := flag.String("name", "", "Project name")
-	module := flag.String("module", "", "Go module name")
-	dir := flag.String("dir", "", "Custom directory name")
-	projectType := flag.String("type", "cli", "Project type: cli, web, or library")
-	noVCS := flag.Bool("no-vcs", false, "Skip VCS initialization")
-	nonInteractive := flag.Bool("non-interactive", false, "Disable interactive mode")
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	// Без аргументов запускаем интерактивный режим
+	if len(args) == 0 {
+		args = []string{"new"}
+	}
 
-	flag.Parse()
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		return runHelp(args[1:])
+	case completeCommand:
+		return runComplete(args[1:])
+	}
 
-	// Non-interactive режим
-	if *nonInteractive || (*name != "" && len(flag.Args()) > 0) {
-		runNonInteractive(name, module, dir, projectType, noVCS, flag.Args())
-		return
+	cmd := findCommand(name)
+	switch {
+	case cmd != nil:
+		args = args[1:]
+	case strings.HasPrefix(name, "-"):
+		// Совместимость со старым вызовом: ginit -name myapp -type web
+		cmd = findCommand("new")
+	default:
+		fmt.Fprintf(os.Stderr, "ginit: unknown command %q\n", name)
+		fmt.Fprintln(os.Stderr, "Run 'ginit help' for the list of commands, or 'ginit new "+name+"' to create a project.")
+		return exitUsage
 	}
 
-	// Interactive режим с BubbleTea
-	runInteractive()
+	return cmd.execute(args)
 }
 
-func runNonInteractive(name *string, module *string, dir *string, projectType *string, noVCS *bool, args []string) {
-	// Логика как раньше
-	if *name == "" && len(args) > 0 {
-		*name = args[0]
+func (c *command) execute(args []string) int {
+	if sub := c.findSubcommand(args); sub != nil {
+		return sub.execute(args[1:])
 	}
 
-	if *name == "" {
-		printUsage()
-		os.Exit(1)
+	fs := flag.NewFlagSet("ginit "+c.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	runner := c.setup(fs)
+
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		c.printUsage(os.Stdout, fs)
+		return exitOK
+	}
+	if err == nil {
+		err = runner(positional)
 	}
 
-	if *module == "" {
-		*module = *name
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, silentError):
+		return exitFailure
+	case errors.As(err, &usageErr), isFlagError(err):
+		fmt.Fprintf(os.Stderr, "ginit %s: %v\n\n", c.name, err)
+		c.printUsage(os.Stderr, fs)
+		return exitUsage
+	default:
+		fmt.Fprintf(os.Stderr, "ginit %s: %v\n", c.name, err)
+		return exitFailure
 	}
+}
+
+// flagParseError marks errors returned by FlagSet.Parse
+type flagParseError struct {
+	err error
+}
+
+func (e flagParseError) Error() string {
+	return e.err.Error()
+}
+
+func isFlagError(err error) bool {
+	var fe flagParseError
+	return errors.As(err, &fe)
+}
+
+// parseInterspersed parses flags that appear anywhere among the positional
+// arguments (ginit new myapp -type web), unlike FlagSet.Parse which stops at
+// the first positional one. Everything after "--" is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, flagParseError{err}
+		}
 
-	if *dir == "" {
-		*dir = *name
+		rest := fs.Args()
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
 	}
+}
 
-	config := generator.Config{
-		ProjectName: *name,
-		ModuleName:  *module,
-		Directory:   *dir,
-		ProjectType: *projectType,
-		InitVCS:     !*noVCS,
+func (c *command) printUsage(w io.Writer, fs *flag.FlagSet) {
+	usage := "Usage: ginit " + c.name
+	if hasFlags(fs) {
+		usage += " [flags]"
+	}
+	if c.args != "" {
+		usage += " " + c.args
 	}
 
-	err := generator.InitProject(config)
-	if err != nil {
-		log.Fatalf("Error initializing project: %v", err)
-	}
-
-	printSuccessMessage(config)
-}
-
-func runInteractive() {
-	// Запускаем TUI
-	p := tea.NewProgram(tui.NewModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running interactive mode: %v\n", err)
-		os.Exit(1)
-	}
-}
-
-func printUsage() {
-	fmt.Println("🚀 Go Project Initializer")
-	fmt.Println("")
-	fmt.Println("Usage: ginit [flags] <project-name>")
-	fmt.Println("")
-	fmt.Println("Flags:")
-	fmt.Println("  -name string          Project name")
-	fmt.Println("  -module string        Go module name (default: project name)")
-	fmt.Println("  -dir string           Custom directory name (default: project name)")
-	fmt.Println("  -type string          Project type: cli, web, or library (default: cli)")
-	fmt.Println("  -no-vcs               Skip VCS initialization")
-	fmt.Println("  -non-interactive      Disable interactive mode")
-	fmt.Println("")
-	fmt.Println("Examples:")
-	fmt.Println("  ginit                             # Interactive mode")
-	fmt.Println("  ginit my-project                  # Quick start")
-	fmt.Println("  ginit -name=myapp -module=github.com/user/myapp")
-	fmt.Println("  ginit my-project -no-vcs -non-interactive")
-}
-
-func printSuccessMessage(config generator.Config) {
-	absPath, _ := filepath.Abs(config.Directory)
-
-	style := tui.DefaultStyle()
-
-	fmt.Println("")
-	fmt.Println(style.SuccessIcon.Render("🎉 ") + style.SuccessText.Render("Project initialized successfully!"))
-	fmt.Println("")
-	fmt.Println(style.Label.Render("📁 Project:   ") + style.Value.Render(config.ProjectName))
-	fmt.Println(style.Label.Render("📦 Module:    ") + style.Value.Render(config.ModuleName))
-	fmt.Println(style.Label.Render("📂 Directory: ") + style.Value.Render(absPath))
-	fmt.Println("")
-	fmt.Println(style.Section.Render("🚀 Next steps:"))
-	fmt.Println("")
-
-	steps := []string{
-		"cd " + config.Directory,
-		"go mod tidy",
-		"go build -o bin/" + config.ProjectName + " ./cmd/" + config.ProjectName,
-		"./bin/" + config.ProjectName,
-	}
-
-	if config.InitVCS {
-		steps = append(steps, "git add .", "git commit -m \"Initial commit\"")
-	}
-
-	for i, step := range steps {
-		emoji := "📂"
-		switch i {
-		case 1:
-			emoji = "🔧"
-		case 2:
-			emoji = "🏗️"
-		case 3:
-			emoji = "▶️"
-		case 4:
-			emoji = "📝"
-		case 5:
-			emoji = "📝"
+	fmt.Fprintln(w, usage)
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, c.long)
+
+	if len(c.subcommands) > 0 {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "Commands:")
+		for _, sub := range c.subcommands {
+			fmt.Fprintf(w, "  %-12s %s\n", strings.TrimPrefix(sub.name, c.name+" "), sub.short)
 		}
-		fmt.Println(style.Label.Render("  "+emoji+" ") + style.Code.Render(step))
 	}
 
-	fmt.Println("")
-	fmt.Println(style.Section.Render("💡 Tips:"))
-	tips := []string{
-		"Use 'go run ./cmd/" + config.ProjectName + "' for quick testing",
-		"Check out the README.md for more details",
-		"Modify internal/config for your needs",
+	if hasFlags(fs) {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "Flags:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
 	}
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
 
-	for _, tip := range tips {
-		fmt.Println(style.Label.Render("  • ") + style.Tip.Render(tip))
+func runHelp(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		return exitOK
 	}
 
-	fmt.Println("")
-	fmt.Println(style.SuccessText.Render("Happy coding!") + " 👨‍💻👩‍💻")
-	fmt.Println("")
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "ginit help: unknown command %q\n", args[0])
+		return exitUsage
+	}
+	for _, name := range args[1:] {
+		sub := cmd.findSubcommand([]string{name})
+		if sub == nil {
+			fmt.Fprintf(os.Stderr, "ginit help: unknown command %q\n", cmd.name+" "+name)
+			return exitUsage
+		}
+		cmd = sub
+	}
+
+	fs := flag.NewFlagSet("ginit "+cmd.name, flag.ContinueOnError)
+	cmd.setup(fs)
+	cmd.printUsage(os.Stdout, fs)
+	return exitOK
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "🚀 Go Project Initializer")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Usage: ginit <command> [flags] [arguments]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Flags may appear before or after arguments.")
+	fmt.Fprintln(w, "Run 'ginit help <command>' for details on a command.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Examples:")
+	fmt.Fprintln(w, "  ginit                                   # Interactive mode")
+	fmt.Fprintln(w, "  ginit new my-project                    # Quick start")
+	fmt.Fprintln(w, "  ginit new myapp -module github.com/user/myapp -type web")
+	fmt.Fprintln(w, "  ginit new myapp -type web -feature docker,database,ci")
+	fmt.Fprintln(w, "  ginit new myapp -preset http-service")
+	fmt.Fprintln(w, "  ginit new myapp -output json -quiet")
+	fmt.Fprintln(w, "  ginit status ./myapp")
+	fmt.Fprintln(w, "  source <(ginit completion bash)         # Shell completion")
+}
+
+// listFlag collects the values of a flag that may be repeated or hold a
+// comma-separated list: -feature docker -feature ci,lint
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// varFlag collects key=value pairs of a repeated flag: -var owner=platform
+type varFlag map[string]string
+
+func (v varFlag) String() string {
+	var pairs []string
+	for key, value := range v {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v varFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return errors.New("expected key=value")
+	}
+	v[key] = val
+	return nil
+}
+
+// logFlags registers -verbose and -quiet and returns a function reading the
+// resulting log level
+func logFlags(fs *flag.FlagSet) func() slog.Level {
+	verbose := fs.Bool("verbose", false, "Show debug output, including go/git command output")
+	quiet := fs.Bool("quiet", false, "Only show errors")
+
+	return func() slog.Level {
+		switch {
+		case *verbose:
+			return slog.LevelDebug
+		case *quiet:
+			return slog.LevelError
+		default:
+			return slog.LevelInfo
+		}
+	}
+}
+
+// newLogger creates the stderr logger used for generator diagnostics
+func newLogger(level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// Время в выводе CLI только мешает
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// loadSettings reads the user settings file; without a user config
+// directory the settings are empty
+func loadSettings() (*ginit.Settings, error) {
+	path, err := ginit.SettingsPath()
+	if err != nil {
+		return &ginit.Settings{}, nil
+	}
+	return ginit.LoadSettings(path)
+}
+
+// loadTemplateIndex reads the installed templates and trusts the keys of
+// the settings; without a user config directory only the built-in types
+// are listed
+func loadTemplateIndex() (*ginit.TemplateIndex, error) {
+	settings, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	userDir, cacheDir, err := ginit.TemplateDirs()
+	if err != nil {
+		userDir, cacheDir = "", ""
+	}
+	ix, err := ginit.LoadTemplateIndex(userDir, cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	ix.Trust(settings.TrustedKeys)
+	return ix, nil
+}
+
+// loadTemplates returns the template index with the project types and
+// templates of the built-in types and the installed template packs. Packs
+// that cannot be registered are reported on stderr and left out.
+func loadTemplates() (*ginit.TemplateIndex, *ginit.TypeRegistry, *ginit.Registry, error) {
+	ix, err := loadTemplateIndex()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	types, registry := ginit.DefaultTypes(), ginit.DefaultRegistry()
+	if err := ix.Register(registry, types); err != nil {
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, tui.WarningStyle.Render("warning: ")+err.Error())
+		}
+	}
+	return ix, types, registry, nil
+}
+
+// projectGenerator returns a generator for an existing project: with the
+// policy of the settings and the installed templates, so projects
+// generated from a template can be upgraded and extended
+func projectGenerator(level slog.Level) (*ginit.Generator, error) {
+	settings, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	_, types, registry, err := loadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return ginit.New(
+		ginit.WithLogger(newLogger(level)),
+		ginit.WithPolicy(settings.Policy),
+		ginit.WithTypes(types),
+		ginit.WithRegistry(registry),
+	), nil
 }
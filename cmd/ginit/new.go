@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newCommand() *command {
+	return &command{
+		name:  "new",
+		args:  "[project-name]",
+		short: "Create a new project",
+		long: `Creates a new Go project. Without a project name (and without -name or
+-non-interactive) the interactive wizard is started.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			name := fs.String("name", "", "Project name")
+			module := fs.String("module", "", "Go module name (default: project name)")
+			dir := fs.String("dir", "", "Custom directory name (default: project name)")
+			projectType := fs.String("type", "", "Project type: "+strings.Join(ginit.DefaultTypes().Names(), ", ")+" or an installed template (default: cli, see 'ginit types')")
+			preset := fs.String("preset", "", "Preset combining a type, features and defaults (see 'ginit presets')")
+			var features listFlag
+			fs.Var(&features, "feature", "Feature to add, may be repeated or comma-separated (see 'ginit features')")
+			templateDir := fs.String("template", "", "Generate from the template pack in this directory instead of a project type")
+			vars := varFlag{}
+			fs.Var(vars, "var", "Template variable as key=value, may be repeated")
+			noVCS := fs.Bool("no-vcs", false, "Skip VCS initialization")
+			nonInteractive := fs.Bool("non-interactive", false, "Disable interactive mode")
+			output := fs.String("output", "text", "Output format for non-interactive mode: text or json")
+			level := logFlags(fs)
+
+			return func(args []string) error {
+				if *output != "text" && *output != "json" {
+					return usageErrorf("unknown output format %q: expected text or json", *output)
+				}
+				if len(args) > 1 {
+					return usageErrorf("expected at most one project name, got %d arguments", len(args))
+				}
+				if len(args) == 1 {
+					if *name != "" && *name != args[0] {
+						return usageErrorf("project name given both as -name and as argument")
+					}
+					*name = args[0]
+				}
+
+				settings, err := loadSettings()
+				if err != nil {
+					return err
+				}
+				presets, err := settings.PresetRegistry()
+				if err != nil {
+					return err
+				}
+
+				config := ginit.Config{
+					ProjectName: *name,
+					ModuleName:  *module,
+					Directory:   *dir,
+					ProjectType: *projectType,
+					Features:    features,
+					InitVCS:     !*noVCS,
+				}
+				if len(vars) > 0 {
+					config.Variables = vars
+				}
+				// Мастер получает значения флагов до применения пресета
+				seed := config
+
+				ix, types, registry, err := loadTemplates()
+				if err != nil {
+					return err
+				}
+				if *templateDir != "" {
+					if *projectType != "" || *preset != "" {
+						return usageErrorf("-template cannot be combined with -type or -preset")
+					}
+					if *name == "" {
+						return usageErrorf("-template needs a project name, the wizard does not support template packs")
+					}
+					pack, err := ginit.LoadPack(*templateDir)
+					if err != nil {
+						return err
+					}
+					if err := ix.VerifyPack(context.Background(), pack.Manifest.Name, *templateDir); err != nil {
+						return err
+					}
+					if err := pack.Register(registry, types); err != nil {
+						return err
+					}
+					config.ProjectType = pack.Manifest.Name
+				}
+				if *preset != "" {
+					p, err := presets.Get(*preset)
+					if err != nil {
+						return usageError{msg: err.Error()}
+					}
+					config = p.Apply(config)
+				}
+				if config.ProjectType == "" {
+					config.ProjectType = "cli"
+				}
+
+				if err := types.Check(config.ProjectType); err != nil {
+					if err := templateError(ix, config.ProjectType); err != nil {
+						return err
+					}
+					return usageError{msg: err.Error()}
+				}
+				if _, err := ginit.DefaultFeatures().Resolve(types.Root(config.ProjectType), config.Features); err != nil {
+					return usageError{msg: err.Error()}
+				}
+
+				// Interactive режим с BubbleTea (JSON вывод возможен только без него)
+				if *name == "" && !*nonInteractive && *output == "text" {
+					if seed.ProjectType != "" && ginit.DefaultTypes().Check(seed.ProjectType) != nil {
+						return usageErrorf("-type %s needs a project name, the wizard does not support installed templates", seed.ProjectType)
+					}
+					return runInteractive(level(), presets.List(), *preset, settings.Policy, seed)
+				}
+
+				return runNonInteractive(config, *output,
+					ginit.WithLogger(newLogger(level())),
+					ginit.WithPolicy(settings.Policy),
+					ginit.WithTypes(types),
+					ginit.WithRegistry(registry),
+				)
+			}
+		},
+	}
+}
+
+func runNonInteractive(config ginit.Config, output string, opts ...ginit.Option) error {
+	if config.ProjectName == "" {
+		return usageErrorf("project name is required")
+	}
+
+	if config.ModuleName == "" {
+		config.ModuleName = config.ProjectName
+	}
+
+	if config.Directory == "" {
+		config.Directory = config.ProjectName
+	}
+
+	g := ginit.New(opts...)
+	result, err := g.Generate(context.Background(), config)
+
+	if output == "json" {
+		if err := printJSONResult(result, err); err != nil {
+			return err
+		}
+		if err != nil {
+			return silentError
+		}
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error initializing project: %w", err)
+	}
+
+	printSuccessMessage(config, result)
+	return nil
+}
+
+// printJSONResult writes the result as a single JSON document to stdout.
+// A failed run still produces a document, with the error in "error".
+func printJSONResult(result *ginit.Result, err error) error {
+	doc := struct {
+		*ginit.Result
+		Error string `json:"error,omitempty"`
+	}{Result: result}
+	if err != nil {
+		doc.Error = err.Error()
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func runInteractive(level slog.Level, presets []ginit.Preset, preset string, policy *ginit.Policy, seed ginit.Config) error {
+	// Запускаем TUI
+	model := tui.NewModel(level, presets, policy).Seed(seed).SelectPreset(preset)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("error running interactive mode: %w", err)
+	}
+
+	// Ошибку генерации TUI уже показал, остается только код выхода
+	if m, ok := final.(tui.Model); ok && m.Err() != nil {
+		if m.Aborted() {
+			return m.Err()
+		}
+		return silentError
+	}
+	return nil
+}
+
+func printSuccessMessage(config ginit.Config, result *ginit.Result) {
+	absPath, _ := filepath.Abs(config.Directory)
+
+	style := tui.DefaultStyle()
+
+	fmt.Println("")
+	fmt.Println(style.SuccessIcon.Render("🎉 ") + style.SuccessText.Render("Project initialized successfully!"))
+	fmt.Println("")
+	fmt.Println(style.Label.Render("📁 Project:   ") + style.Value.Render(config.ProjectName))
+	fmt.Println(style.Label.Render("📦 Module:    ") + style.Value.Render(config.ModuleName))
+	fmt.Println(style.Label.Render("📂 Directory: ") + style.Value.Render(absPath))
+	if len(result.Features) > 0 {
+		fmt.Println(style.Label.Render("🧩 Features:  ") + style.Value.Render(strings.Join(result.Features, ", ")))
+	}
+	fmt.Println("")
+	fmt.Println(style.Section.Render("🚀 Next steps:"))
+	fmt.Println("")
+
+	for i, step := range result.NextSteps {
+		emoji := "▶️"
+		switch {
+		case i == 0:
+			emoji = "📂"
+		case strings.HasPrefix(step, "git "):
+			emoji = "📝"
+		case strings.HasPrefix(step, "go mod"):
+			emoji = "🔧"
+		case strings.HasPrefix(step, "go build"):
+			emoji = "🏗️"
+		}
+		fmt.Println(style.Label.Render("  "+emoji+" ") + style.Code.Render(step))
+	}
+
+	fmt.Println("")
+	fmt.Println(style.Section.Render("💡 Tips:"))
+	tips := []string{
+		"Use 'go run ./cmd/" + config.ProjectName + "' for quick testing",
+		"Check out the README.md for more details",
+		"Modify internal/config for your needs",
+	}
+
+	for _, tip := range tips {
+		fmt.Println(style.Label.Render("  • ") + style.Tip.Render(tip))
+	}
+
+	fmt.Println("")
+	fmt.Println(style.SuccessText.Render("Happy coding!") + " 👨‍💻👩‍💻")
+	fmt.Println("")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+)
+
+func policyCommand() *command {
+	return &command{
+		name:  "policy",
+		short: "Show the policy new projects must comply with",
+		long: `Shows the policy of the settings file. 'ginit new' and 'ginit add' refuse
+projects that do not comply with it and explain which rules are broken.
+
+The policy is the "policy" object of the settings file and of the files it
+includes, so an organisation can share it:
+
+  {
+    "policy": {
+      "name": "acme",
+      "required_features": ["license", "ci", "lint"],
+      "forbidden_features": ["gitlab-ci"],
+      "module_prefixes": ["github.com/acme/"],
+      "name_pattern": "^[a-z][a-z0-9-]*$"
+    }
+  }
+
+Required and forbidden features of included policies are combined. The
+module prefixes and the name pattern of the including file can only narrow
+included ones: a project must match the prefixes and the name patterns of
+every file, so a user file cannot loosen the policy it includes.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usageErrorf("unexpected arguments")
+				}
+
+				settings, err := loadSettings()
+				if err != nil {
+					return err
+				}
+
+				style := tui.DefaultStyle()
+				p := settings.Policy
+				if p == nil {
+					fmt.Println(style.Label.Render("No policy configured."))
+					return nil
+				}
+
+				rules := []struct{ label, value string }{
+					{"Name", p.Name},
+					{"Required features", strings.Join(p.RequiredFeatures, ", ")},
+					{"Forbidden features", strings.Join(p.ForbiddenFeatures, ", ")},
+					{"Module prefixes", strings.Join(p.ModulePrefixes, ", ")},
+					{"Name pattern", strings.Join(p.NamePatterns(), " and ")},
+				}
+				for _, r := range rules {
+					if r.value != "" {
+						fmt.Println(style.Label.Render(fmt.Sprintf("%-20s", r.label)) + style.Value.Render(r.value))
+					}
+				}
+				return nil
+			}
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+func presetsCommand() *command {
+	return &command{
+		name:  "presets",
+		short: "List available presets",
+		long: `Lists the presets that can be selected with 'ginit new -preset'. A preset
+combines a project type, features and defaults such as the module prefix.
+
+Besides the built-in presets, ginit reads presets from the settings file
+(` + "$GINIT_CONFIG" + ` or ginit/config.json in the user config directory):
+
+  {
+    "include": ["/etc/ginit/company.json"],
+    "presets": [{
+      "name": "company-http-service",
+      "description": "HTTP service following company conventions",
+      "type": "web",
+      "features": ["database", "metrics", "docker", "ci"],
+      "module_prefix": "github.com/myorg/"
+    }]
+  }
+
+Included files have the same format and let an organisation share presets;
+presets of the including file override included ones with the same name.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usageErrorf("unexpected arguments")
+				}
+
+				presets, err := loadPresets()
+				if err != nil {
+					return err
+				}
+
+				style := tui.DefaultStyle()
+				for _, p := range presets.List() {
+					fmt.Println(style.Value.Render(fmt.Sprintf("%-20s", p.Name)) + style.Label.Render(p.Description))
+					fmt.Println(style.Label.Render("                    " + p.Summary()))
+				}
+				return nil
+			}
+		},
+	}
+}
+
+// loadPresets returns the built-in presets together with the presets of
+// the settings file
+func loadPresets() (*ginit.PresetRegistry, error) {
+	settings, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	return settings.PresetRegistry()
+}
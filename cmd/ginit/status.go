@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+func statusCommand() *command {
+	return &command{
+		name:  "status",
+		args:  "[dir]",
+		short: "Show which generated files were changed",
+		long: `Compares the files of a project generated by ginit with the versions that
+were generated, using the checksums in ` + ginit.ManifestFile + `. The directory
+defaults to the current one.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				dir, err := dirArg(args)
+				if err != nil {
+					return err
+				}
+
+				m, statuses, err := ginit.Status(dir)
+				if err != nil {
+					return err
+				}
+
+				style := tui.DefaultStyle()
+				fmt.Println(style.Label.Render("📁 Project: ") + style.Value.Render(m.Project))
+				fmt.Println(style.Label.Render("📦 Module:  ") + style.Value.Render(m.Module))
+				fmt.Println(style.Label.Render("🧩 Type:    ") + style.Value.Render(m.Type))
+				if len(m.Features) > 0 {
+					fmt.Println(style.Label.Render("✨ Features: ") + style.Value.Render(strings.Join(m.Features, ", ")))
+				}
+				fmt.Println("")
+
+				counts := make(map[ginit.FileState]int)
+				for _, s := range statuses {
+					counts[s.State]++
+					if s.State != ginit.FileUnchanged {
+						fmt.Printf("  %-10s %s\n", s.State, s.Path)
+					}
+				}
+				if counts[ginit.FileUnchanged] < len(statuses) {
+					fmt.Println("")
+				}
+				fmt.Printf("%d unchanged, %d modified, %d missing\n",
+					counts[ginit.FileUnchanged], counts[ginit.FileModified], counts[ginit.FileMissing])
+				return nil
+			}
+		},
+		complete: completeDirArg,
+	}
+}
+
+// dirArg returns the optional directory argument, "." by default
+func dirArg(args []string) (string, error) {
+	switch len(args) {
+	case 0:
+		return ".", nil
+	case 1:
+		return args[0], nil
+	default:
+		return "", usageErrorf("expected at most one directory")
+	}
+}
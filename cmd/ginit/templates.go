@@ -0,0 +1,363 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+func templatesCommand() *command {
+	return &command{
+		name:  "templates",
+		args:  "[command]",
+		short: "List, inspect and install templates",
+		long: `Lists the templates projects can be generated from: the built-in project
+types and the installed template packs. Without a command, the templates are
+listed.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usageErrorf("unknown command %q", args[0])
+				}
+				return listInstalledTemplates()
+			}
+		},
+		subcommands: []*command{
+			templatesListCommand(),
+			templatesShowCommand(),
+			templatesInstallCommand(),
+			templatesUpdateCommand(),
+			templatesRemoveCommand(),
+			templatesChecksumCommand(),
+			templatesVerifyCommand(),
+			templatesLintCommand(),
+			templatesExtractCommand(),
+		},
+	}
+}
+
+func templatesListCommand() *command {
+	return &command{
+		name:  "templates list",
+		short: "List available templates",
+		long: `Lists the templates projects can be generated from with their version,
+source, required Go version and variables:
+
+  built-in  project types compiled into ginit
+  user      template packs in the templates directory next to the settings
+            file, e.g. ~/.config/ginit/templates/<name>
+  cache     template packs added with 'ginit templates install'
+
+A user pack overrides a cached one with the same name, both override a
+built-in type. Packs that fail to load are listed with their error.
+
+With -files, the names of the file templates are listed instead.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			files := fs.Bool("files", false, "List the file templates of the project types")
+
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usageErrorf("unexpected arguments")
+				}
+				if *files {
+					return listTemplates()
+				}
+				return listInstalledTemplates()
+			}
+		},
+	}
+}
+
+func templatesShowCommand() *command {
+	return &command{
+		name:  "templates show",
+		args:  "<name>",
+		short: "Describe a template or print a file template",
+		long: `Prints the manifest and the file tree of a template from 'ginit templates
+list', or the source of a file template from 'ginit templates list -files'.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) != 1 {
+					return usageErrorf("expected exactly one template name")
+				}
+
+				ix, types, registry, err := loadTemplates()
+				if err != nil {
+					return err
+				}
+				if t, ok := ix.Lookup(args[0]); ok {
+					return showInstalledTemplate(t, types, registry)
+				}
+				if err := templateError(ix, args[0]); err != nil {
+					return err
+				}
+
+				source, ok := registry.Lookup(args[0])
+				if !ok {
+					return fmt.Errorf("template %q: %w", args[0], ginit.ErrTemplateNotFound)
+				}
+				fmt.Print(source)
+				return nil
+			}
+		},
+		complete: func(args []string) []string {
+			if len(args) > 0 {
+				return nil
+			}
+			return append(templateNames(), ginit.DefaultRegistry().Names()...)
+		},
+	}
+}
+
+func templatesLintCommand() *command {
+	return &command{
+		name:  "templates lint",
+		args:  "<dir>",
+		short: "Check a template pack for errors",
+		long: `Checks the template pack in dir without generating a project. A pack is a
+directory with a ` + ginit.PackManifestFile + ` manifest and a files/ directory of
+templates:
+
+  {
+    "name": "company-service",
+    "version": "1.0.0",
+    "description": "HTTP service following company conventions",
+    "variables": [{"name": "owner", "description": "Owning team", "required": true}],
+    "dependencies": ["github.com/caarlos0/env/v11"]
+  }
+
+File paths below files/ are templates too, e.g. cmd/{{.ProjectName}}/main.go.tmpl;
+the .tmpl suffix is dropped. Templates read variables as {{.Vars.owner}}.
+
+Lint reports templates and paths that do not parse, references to unknown
+fields and undeclared variables, malformed conditions, paths that are invalid,
+leave the project or collide, and rendered Go code that does not compile.
+Unused variables are reported as warnings. Exits with status 1 on errors.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) != 1 {
+					return usageErrorf("expected exactly one template pack directory")
+				}
+
+				issues, err := ginit.LintPack(args[0])
+				if err != nil {
+					return err
+				}
+
+				errs := 0
+				for _, issue := range issues {
+					fmt.Println(issue)
+					if !issue.Warning {
+						errs++
+					}
+				}
+				if errs > 0 {
+					return fmt.Errorf("%d errors in %s", errs, args[0])
+				}
+				return nil
+			}
+		},
+		complete: completeDirArg,
+	}
+}
+
+func listTemplates() error {
+	for _, name := range ginit.DefaultRegistry().Names() {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+// listInstalledTemplates prints the template index as a table
+func listInstalledTemplates() error {
+	ix, err := loadTemplateIndex()
+	if err != nil {
+		return err
+	}
+	// Ошибки регистрации попадают в Error записей и выводятся в таблице
+	_ = ix.Register(ginit.DefaultRegistry(), ginit.DefaultTypes())
+	templates := ix.List()
+
+	columns := []string{"NAME", "VERSION", "SOURCE", "GO", "VARIABLES"}
+	rows := make([][]string, len(templates))
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = len(c)
+	}
+	for i, t := range templates {
+		var vars []string
+		for _, v := range t.Variables {
+			vars = append(vars, v.Name)
+		}
+		rows[i] = []string{t.Name, t.Version, templateSource(t), t.GoVersion, strings.Join(vars, ",")}
+		for j, cell := range rows[i] {
+			widths[j] = max(widths[j], len(cell))
+		}
+	}
+
+	style := tui.DefaultStyle()
+	header := ""
+	for i, c := range columns {
+		header += fmt.Sprintf("%-*s", widths[i]+2, c)
+	}
+	fmt.Println(style.Section.Render(header + "DESCRIPTION"))
+
+	for i, t := range templates {
+		line := style.Value.Render(fmt.Sprintf("%-*s", widths[0]+2, rows[i][0]))
+		for j := 1; j < len(columns); j++ {
+			line += style.Label.Render(fmt.Sprintf("%-*s", widths[j]+2, rows[i][j]))
+		}
+		switch used, _ := ix.Lookup(t.Name); {
+		case t.Error != "":
+			// Подробности, например список измененных файлов, выводит show
+			msg, _, _ := strings.Cut(t.Error, "\n")
+			line += tui.StepFailedStyle.Render(msg)
+		case used.Kind != t.Kind || used.Dir != t.Dir:
+			line += style.Label.Render(t.Description + " (overridden by " + used.Kind + ")")
+		default:
+			line += style.Label.Render(t.Description)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// templateSource describes where a template comes from: built-in, user or
+// the origin of a cached pack
+func templateSource(t ginit.InstalledTemplate) string {
+	if t.Kind != ginit.KindCache {
+		return t.Kind
+	}
+	if t.Ref != "" {
+		return t.Origin + "@" + t.Ref
+	}
+	return t.Origin
+}
+
+// showInstalledTemplate prints the manifest of t and the files of a
+// project generated from it
+func showInstalledTemplate(t ginit.InstalledTemplate, types *ginit.TypeRegistry, registry *ginit.Registry) error {
+	style := tui.DefaultStyle()
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Println(style.Label.Render(fmt.Sprintf("%-13s", label+":")) + style.Value.Render(value))
+		}
+	}
+
+	field("Name", t.Name)
+	field("Version", t.Version)
+	field("Description", t.Description)
+	field("Source", templateSource(t))
+	field("Commit", t.Commit)
+	field("Checksum", t.Checksum)
+	field("Signed by", t.SignedBy)
+	field("Directory", t.Dir)
+	field("Extends", t.Extends)
+	field("Go version", t.GoVersion)
+
+	// Пример проекта: обязательные переменные получают свои имена
+	config := ginit.Config{
+		ProjectName: "app",
+		ModuleName:  "example.com/app",
+		ProjectType: t.Name,
+		Variables:   map[string]string{},
+	}
+	if len(t.Variables) > 0 {
+		fmt.Println("")
+		fmt.Println(style.Section.Render("Variables:"))
+		for _, v := range t.Variables {
+			detail := v.Description
+			switch {
+			case v.Required:
+				detail += " (required)"
+				config.Variables[v.Name] = "<" + v.Name + ">"
+			case v.Default != "":
+				detail += " (default " + v.Default + ")"
+			}
+			fmt.Println(style.Value.Render(fmt.Sprintf("  %-14s", v.Name)) + style.Label.Render(strings.TrimSpace(detail)))
+		}
+	}
+
+	plan, err := ginit.New(ginit.WithTypes(types), ginit.WithRegistry(registry)).Plan(config)
+	if err != nil {
+		return err
+	}
+	if len(plan.Dependencies) > 0 {
+		fmt.Println("")
+		fmt.Println(style.Section.Render("Dependencies:"))
+		for _, d := range plan.Dependencies {
+			fmt.Println(style.Label.Render("  " + d))
+		}
+	}
+
+	files := make([]string, len(plan.Files))
+	for i, f := range plan.Files {
+		files[i] = f.Path
+	}
+	fmt.Println("")
+	fmt.Println(style.Section.Render("Files of project " + config.ProjectName + ":"))
+	printTree(plan.Directories, files)
+	return nil
+}
+
+// printTree prints the directories and files of a project, given as
+// slash-separated paths, as an indented tree with directories first
+func printTree(dirs, files []string) {
+	children := make(map[string][]string)
+	isDir := make(map[string]bool)
+	add := func(p string, dir bool) {
+		for ; p != "."; p = path.Dir(p) {
+			if _, ok := isDir[p]; ok {
+				isDir[p] = isDir[p] || dir
+				return
+			}
+			isDir[p] = dir
+			children[path.Dir(p)] = append(children[path.Dir(p)], p)
+			dir = true
+		}
+	}
+	for _, d := range dirs {
+		add(d, true)
+	}
+	for _, f := range files {
+		add(f, false)
+	}
+
+	style := tui.DefaultStyle()
+	var walk func(dir, indent string)
+	walk = func(dir, indent string) {
+		entries := children[dir]
+		sort.Slice(entries, func(i, j int) bool {
+			if isDir[entries[i]] != isDir[entries[j]] {
+				return isDir[entries[i]]
+			}
+			return entries[i] < entries[j]
+		})
+		for _, e := range entries {
+			if isDir[e] {
+				fmt.Println(style.Label.Render(indent + path.Base(e) + "/"))
+				walk(e, indent+"  ")
+			} else {
+				fmt.Println(style.Value.Render(indent + path.Base(e)))
+			}
+		}
+	}
+	walk(".", "  ")
+}
+
+// templateError returns why the installed template name cannot be used, or
+// nil if there is no such template
+func templateError(ix *ginit.TemplateIndex, name string) error {
+	for _, t := range ix.List() {
+		if t.Name == name && t.Error != "" {
+			return fmt.Errorf("template %s cannot be used: %s", name, t.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+)
+
+func typesCommand() *command {
+	return &command{
+		name:  "types",
+		short: "List available project types",
+		long: `Lists the project types accepted by 'ginit new -type' with their descriptions,
+the installed templates included.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usageErrorf("unexpected arguments")
+				}
+
+				_, types, _, err := loadTemplates()
+				if err != nil {
+					return err
+				}
+
+				style := tui.DefaultStyle()
+				for _, t := range types.List() {
+					fmt.Println(style.Value.Render(fmt.Sprintf("%-10s", t.Name)) + style.Label.Render(t.Description))
+				}
+				return nil
+			}
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+func upgradeCommand() *command {
+	return &command{
+		name:  "upgrade",
+		args:  "[dir]",
+		short: "Update a project to the current templates",
+		long: `Re-renders a project generated by ginit with the current templates and
+updates the files that were not modified since generation. Modified files are
+reported as conflicts and left untouched, which fails the command. The
+directory defaults to the current one.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			dryRun := fs.Bool("dry-run", false, "Only show what would change")
+			level := logFlags(fs)
+
+			return func(args []string) error {
+				dir, err := dirArg(args)
+				if err != nil {
+					return err
+				}
+
+				g, err := projectGenerator(level())
+				if err != nil {
+					return err
+				}
+				changes, err := g.Upgrade(dir, *dryRun)
+				if err != nil {
+					return err
+				}
+
+				return printChanges(changes, *dryRun, "Project is up to date.")
+			}
+		},
+		complete: completeDirArg,
+	}
+}
+
+// printChanges prints the files Upgrade or AddFeatures changed, followed by
+// done. Files kept because of conflicts fail the command.
+func printChanges(changes []ginit.FileUpgrade, dryRun bool, done string) error {
+	conflicts := printChangeList(changes)
+
+	if dryRun {
+		fmt.Println("Dry run, nothing was written.")
+	}
+	if conflicts > 0 {
+		return fmt.Errorf("%d file(s) were modified locally and kept; merge template changes by hand", conflicts)
+	}
+	if !dryRun {
+		fmt.Println(done)
+	}
+	return nil
+}
+
+// printChangeList prints the files that change and returns the number of
+// conflicts
+func printChangeList(changes []ginit.FileUpgrade) int {
+	conflicts := 0
+	for _, c := range changes {
+		if c.Action == ginit.UpgradeConflict {
+			conflicts++
+		}
+		if c.Action != ginit.UpgradeUnchanged {
+			fmt.Printf("  %-13s %s\n", c.Action, c.Path)
+		}
+	}
+	return conflicts
+}
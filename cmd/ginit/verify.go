@@ -0,0 +1,399 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/cardinalnsk/ginit/internal/tui"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+)
+
+// verifyStages are the columns of the verify matrix, in the order they run
+var verifyStages = []string{"generate", "vet", "build", "test"}
+
+// verifyOutputLines is how many trailing output lines of a failed stage
+// are printed
+const verifyOutputLines = 15
+
+// combination is a project generated by 'templates verify'
+type combination struct {
+	name      string
+	typeName  string
+	features  []string
+	variables map[string]string
+}
+
+// stageResult is the outcome of one stage of a combination
+type stageResult struct {
+	ok     bool
+	output string
+}
+
+func templatesVerifyCommand() *command {
+	return &command{
+		name:  "templates verify",
+		short: "Generate and build every template combination",
+		long: `Generates a project for every combination of project type, preset and
+features into a temporary directory and runs go vet, go build ./... and
+go test ./... in it, then prints a pass/fail matrix. Per project type the
+combinations are the type alone, the type with each feature it supports,
+with every compatible pair of them and with all compatible features; pairs
+that only add what a single feature already requires are left out. Every
+preset is verified as well. Combinations of three or more features other
+than "all" are not built.
+Installed templates are verified like the built-in types, with their
+required variables set to their names.
+
+Dependencies are resolved through a file-based GOPROXY, by default the
+download cache of the local module cache, so verification works offline once
+the dependencies were downloaded. Exits with status 1 if any combination
+fails.`,
+		setup: func(fs *flag.FlagSet) runFunc {
+			var types listFlag
+			fs.Var(&types, "type", "Only verify these project types, may be repeated or comma-separated")
+			proxy := fs.String("proxy", "", "GOPROXY directory or URL (default: the module cache download directory)")
+			keep := fs.Bool("keep", false, "Keep the generated projects")
+
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usageErrorf("unexpected arguments")
+				}
+				_, registered, registry, err := loadTemplates()
+				if err != nil {
+					return err
+				}
+				for _, t := range types {
+					if err := registered.Check(t); err != nil {
+						return usageError{msg: err.Error()}
+					}
+				}
+
+				presets, err := loadPresets()
+				if err != nil {
+					return err
+				}
+
+				dir, err := os.MkdirTemp("", "ginit-verify-")
+				if err != nil {
+					return err
+				}
+				if *keep {
+					fmt.Println(tui.DefaultStyle().Label.Render("Projects are kept in " + dir))
+				} else {
+					defer os.RemoveAll(dir)
+				}
+
+				env, err := verifyEnv(dir, *proxy)
+				if err != nil {
+					return err
+				}
+
+				return verifyTemplates(dir, verifyCombinations(types, registered, presets.List()), env,
+					ginit.WithTypes(registered), ginit.WithRegistry(registry))
+			}
+		},
+	}
+}
+
+// verifyCombinations lists the combinations to verify for the given
+// project types of types, all if none are given
+func verifyCombinations(only []string, types *ginit.TypeRegistry, presets []ginit.Preset) []combination {
+	features := ginit.DefaultFeatures()
+
+	var combinations []combination
+	for _, t := range types.Names() {
+		if len(only) > 0 && !slices.Contains(only, t) {
+			continue
+		}
+
+		// Фичи шаблона определяются встроенным типом, который он расширяет
+		root, vars := types.Root(t), sampleVariables(types, t)
+		combinations = append(combinations, combination{name: t, typeName: t, variables: vars})
+		// seen holds the resolved feature sets already verified, a feature
+		// pulls in the features it requires
+		seen := make(map[string]bool)
+		var supported, all []string
+		for _, f := range features.Names() {
+			resolved, err := features.Resolve(root, []string{f})
+			if err != nil {
+				continue
+			}
+			seen[featureSet(resolved)] = true
+			supported = append(supported, f)
+			combinations = append(combinations, combination{name: t + " + " + f, typeName: t, features: []string{f}, variables: vars})
+			if _, err := features.Resolve(root, append(slices.Clone(all), f)); err == nil {
+				all = append(all, f)
+			}
+		}
+		// Пары ловят фичи, которые ломают друг друга
+		for i, a := range supported {
+			for _, b := range supported[i+1:] {
+				resolved, err := features.Resolve(root, []string{a, b})
+				if err != nil || seen[featureSet(resolved)] {
+					continue
+				}
+				seen[featureSet(resolved)] = true
+				combinations = append(combinations, combination{name: t + " + " + a + " + " + b, typeName: t, features: []string{a, b}, variables: vars})
+			}
+		}
+		if resolved, err := features.Resolve(root, all); len(all) > 1 && err == nil && !seen[featureSet(resolved)] {
+			combinations = append(combinations, combination{name: t + " + all", typeName: t, features: all, variables: vars})
+		}
+	}
+
+	for _, p := range presets {
+		if len(only) > 0 && !slices.Contains(only, p.Type) {
+			continue
+		}
+		combinations = append(combinations, combination{
+			name:      "preset " + p.Name,
+			typeName:  p.Type,
+			features:  p.Features,
+			variables: sampleVariables(types, p.Type),
+		})
+	}
+	return combinations
+}
+
+// featureSet identifies resolved features by their names, in resolution
+// order
+func featureSet(features []ginit.Feature) string {
+	names := make([]string, len(features))
+	for i, f := range features {
+		names[i] = f.Info().Name
+	}
+	return strings.Join(names, ",")
+}
+
+// sampleVariables returns values for the required variables of the type
+// name: their own names
+func sampleVariables(types *ginit.TypeRegistry, name string) map[string]string {
+	t, ok := types.Lookup(name)
+	if !ok {
+		return nil
+	}
+	vt, ok := t.(ginit.VariableType)
+	if !ok {
+		return nil
+	}
+
+	var vars map[string]string
+	for _, v := range vt.Variables() {
+		if v.Required {
+			if vars == nil {
+				vars = make(map[string]string)
+			}
+			vars[v.Name] = v.Name
+		}
+	}
+	return vars
+}
+
+// verifyEnv returns the environment the go commands of verification run
+// with: dependencies come from proxy only and nothing is downloaded from
+// the network. Without proxy the module cache is used, see
+// moduleCacheProxy.
+func verifyEnv(dir, proxy string) ([]string, error) {
+	if proxy == "" {
+		var err error
+		if proxy, err = moduleCacheProxy(filepath.Join(dir, "proxy")); err != nil {
+			return nil, err
+		}
+	} else if !strings.Contains(proxy, "://") {
+		var err error
+		if proxy, err = fileURL(proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	return []string{
+		"GOPROXY=" + proxy,
+		"GOSUMDB=off",
+		"GOFLAGS=-mod=mod",
+		"GOTOOLCHAIN=local",
+	}, nil
+}
+
+// moduleCacheProxy returns a GOPROXY serving the modules of the local
+// module cache. The download cache has the layout of a proxy but lacks the
+// @v/list files go needs to find the latest version, so they are written
+// to dir, which is tried first, for every version whose zip is cached.
+func moduleCacheProxy(dir string) (string, error) {
+	out, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err != nil {
+		return "", fmt.Errorf("go env GOMODCACHE: %w", err)
+	}
+	cache := filepath.Join(strings.TrimSpace(string(out)), "cache", "download")
+
+	err = filepath.WalkDir(cache, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || d.Name() != "@v" {
+			return err
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		var versions []string
+		for _, e := range entries {
+			if v, ok := strings.CutSuffix(e.Name(), ".zip"); ok {
+				versions = append(versions, v+"\n")
+			}
+		}
+
+		rel, err := filepath.Rel(cache, path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(dir, rel), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, rel, "list"), []byte(strings.Join(versions, "")), 0644)
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("index module cache: %w", err)
+	}
+
+	lists, err := fileURL(dir)
+	if err != nil {
+		return "", err
+	}
+	modules, err := fileURL(cache)
+	if err != nil {
+		return "", err
+	}
+	return lists + "," + modules, nil
+}
+
+// fileURL returns the file:// URL of a local directory
+func fileURL(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String(), nil
+}
+
+// verifyTemplates generates and builds every combination in a directory
+// below dir with a generator created with opts and prints the matrix as
+// results come in
+func verifyTemplates(dir string, combinations []combination, env []string, opts ...ginit.Option) error {
+	style := tui.DefaultStyle()
+
+	width := len("combination")
+	for _, c := range combinations {
+		width = max(width, len(c.name))
+	}
+
+	header := fmt.Sprintf("%-*s", width+2, "COMBINATION")
+	for _, stage := range verifyStages {
+		header += fmt.Sprintf("%-10s", strings.ToUpper(stage))
+	}
+	fmt.Println(style.Section.Render(header))
+
+	type failure struct {
+		combination string
+		stage       string
+		output      string
+	}
+	var failures []failure
+	failed := 0
+
+	for i, c := range combinations {
+		results := verifyCombination(filepath.Join(dir, fmt.Sprintf("%02d", i+1)), c, env, opts)
+
+		line := style.Value.Render(fmt.Sprintf("%-*s", width+2, c.name))
+		for j, stage := range verifyStages {
+			switch {
+			case j >= len(results):
+				line += style.Label.Render(fmt.Sprintf("%-10s", "-"))
+			case results[j].ok:
+				line += tui.SelectedStyle.Render(fmt.Sprintf("%-10s", "✓"))
+			default:
+				line += tui.StepFailedStyle.Render(fmt.Sprintf("%-10s", "✗"))
+				failures = append(failures, failure{c.name, stage, results[j].output})
+			}
+		}
+		fmt.Println(line)
+		if !results[len(results)-1].ok {
+			failed++
+		}
+	}
+
+	for _, f := range failures {
+		fmt.Println("")
+		fmt.Println(tui.StepFailedStyle.Render(f.combination + ": " + f.stage + " failed"))
+		fmt.Println(style.Code.Render(lastLines(f.output, verifyOutputLines)))
+	}
+
+	fmt.Println("")
+	if failed > 0 {
+		fmt.Println(tui.StepFailedStyle.Render(fmt.Sprintf("%d of %d combinations failed", failed, len(combinations))))
+		return silentError
+	}
+	fmt.Println(style.SuccessText.Render(fmt.Sprintf("All %d combinations passed", len(combinations))))
+	return nil
+}
+
+// verifyCombination runs the stages for c in dir until one fails
+func verifyCombination(dir string, c combination, env []string, opts []ginit.Option) []stageResult {
+	ctx := context.Background()
+
+	g := ginit.New(append(opts, ginit.WithEnv(env...))...)
+	_, err := g.Generate(ctx, ginit.Config{
+		ProjectName: "app",
+		ModuleName:  "example.com/app",
+		Directory:   dir,
+		ProjectType: c.typeName,
+		Features:    c.features,
+		Variables:   c.variables,
+	})
+	if err != nil {
+		output := err.Error()
+		var cmdErr *ginit.CommandError
+		if errors.As(err, &cmdErr) {
+			output += "\n" + strings.Join(cmdErr.Output, "\n")
+		}
+		return []stageResult{{output: output}}
+	}
+	results := []stageResult{{ok: true}}
+
+	for _, args := range [][]string{
+		{"vet", "./..."},
+		{"build", "./..."},
+		{"test", "./..."},
+	} {
+		cmd := exec.CommandContext(ctx, "go", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		out, err := cmd.CombinedOutput()
+
+		results = append(results, stageResult{ok: err == nil, output: string(out)})
+		if err != nil {
+			break
+		}
+	}
+	return results
+}
+
+// lastLines returns the last n lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,30 +1,69 @@
 package tui
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"log/slog"
 	"strings"
 
-	"github.com/cardinalnsk/ginit/internal/generator"
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type Model struct {
-	step            int
-	projectName     textinput.Model
-	moduleName      textinput.Model
-	directory       textinput.Model
-	projectType     string
-	initVCS         bool
-	quitting        bool
-	success         bool
-	error           error
-	config          generator.Config
-	creatingProject bool
+	step        int
+	projectName textinput.Model
+	moduleName  textinput.Model
+	directory   textinput.Model
+	choices     []choice
+	choice      int
+	initVCS     bool
+	// projectType, features and variables come from command line flags,
+	// the wizard does not ask for them
+	projectType string
+	features    []string
+	variables   map[string]string
+	policy      *ginit.Policy
+	// violations is set when the chosen settings break the policy
+	violations *ginit.PolicyError
+	quitting   bool
+	success    bool
+	error      error
+	config     ginit.Config
+	logLevel   slog.Level
+
+	// Состояние экрана создания проекта
+	spinner  spinner.Model
+	log      viewport.Model
+	steps    []stepStatus
+	logLines []string
+	events   chan tea.Msg
+	// cancel stops the running generation, aborting is set once it was
+	// called and the model waits for the generator to return
+	cancel   context.CancelFunc
+	aborting bool
+	done     bool
+	width    int
+	height   int
 }
 
-func NewModel() Model {
+// choice is an entry of the project type step: a preset or a plain
+// project type
+type choice struct {
+	title       string
+	description string
+	projectType string
+	preset      *ginit.Preset
+}
+
+// NewModel returns the wizard. presets are offered before the project
+// types in the type step; projects that do not comply with policy are
+// refused before generation starts.
+func NewModel(logLevel slog.Level, presets []ginit.Preset, policy *ginit.Policy) Model {
 	// Инициализируем поля ввода
 	project := textinput.New()
 	project.Placeholder = "my-awesome-app"
@@ -50,8 +89,77 @@ func NewModel() Model {
 		projectName: project,
 		moduleName:  module,
 		directory:   dir,
+		choices:     typeChoices(presets),
 		initVCS:     true,
+		policy:      policy,
+		logLevel:    logLevel,
+		spinner:     spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(SpinnerStyle)),
+		log:         viewport.New(logWidth, logHeight),
+	}
+}
+
+func typeChoices(presets []ginit.Preset) []choice {
+	var choices []choice
+	for _, p := range presets {
+		description := p.Summary()
+		if p.Description != "" {
+			description = p.Description + " (" + description + ")"
+		}
+		choices = append(choices, choice{
+			title:       "Preset: " + p.Name,
+			description: description,
+			projectType: p.Type,
+			preset:      &p,
+		})
+	}
+	for _, t := range ginit.DefaultTypes().List() {
+		choices = append(choices, choice{title: t.Title, description: t.Description, projectType: t.Name})
+	}
+	return choices
+}
+
+// SelectPreset preselects the named preset in the type step; unknown
+// names are ignored
+func (m Model) SelectPreset(name string) Model {
+	for i, c := range m.choices {
+		if c.preset != nil && c.preset.Name == name {
+			m.choice = i
+		}
+	}
+	return m
+}
+
+// Seed fills the wizard with the values of config given on the command
+// line: the module, the directory, Git initialization, the preselected
+// project type and the features and variables, which are added to the
+// generated project. A preset selected afterwards keeps the project type.
+func (m Model) Seed(config ginit.Config) Model {
+	m.moduleName.SetValue(config.ModuleName)
+	m.directory.SetValue(config.Directory)
+	m.initVCS = config.InitVCS
+	m.projectType = config.ProjectType
+	m.features = config.Features
+	m.variables = config.Variables
+
+	if m.projectType != "" {
+		for i, c := range m.choices {
+			if c.preset == nil && c.projectType == m.projectType {
+				m.choice = i
+			}
+		}
 	}
+	return m
+}
+
+// Err returns the error project generation failed with, if any
+func (m Model) Err() error {
+	return m.error
+}
+
+// Aborted reports whether generation was aborted with Ctrl+C. Err then
+// tells where the incomplete project was left.
+func (m Model) Aborted() bool {
+	return m.aborting
 }
 
 func (m Model) Init() tea.Cmd {
@@ -63,15 +171,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	}
 
-	// Handle final step (project creation result)
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width, m.height = size.Width, size.Height
+		m.resizeLog()
+	}
+
+	// Handle final step (project creation progress and result)
 	if m.step == 5 {
-		switch msg.(type) {
-		case tea.KeyMsg:
-			// Any key to quit after seeing result
-			m.quitting = true
-			return m, tea.Quit
-		}
-		return m, nil
+		return m.updateProgress(msg)
 	}
 
 	switch msg := msg.(type) {
@@ -82,10 +189,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 
 		case "enter":
-			if m.creatingProject {
-				// Игнорируем Enter во время создания проекта
-				return m, nil
-			}
 			if m.step < 4 {
 				m.step++
 				// Передаем фокус следующему полю
@@ -96,15 +199,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				return m, nil
 			} else {
+				// Несоответствие политике показываем до начала генерации,
+				// чтобы можно было вернуться и исправить выбор
+				m.violations = nil
+				_, err := ginit.New(ginit.WithPolicy(m.policy)).Plan(m.buildConfig())
+				if errors.As(err, &m.violations) {
+					return m, nil
+				}
+
 				// Создаем проект
 				m.step = 5 // Переходим к шагу создания проекта
-				m.creatingProject = true
-				return m, m.createProject()
+				return m, m.startProject()
 			}
 
 		case "backspace":
 			if m.step > 0 {
 				m.step--
+				m.violations = nil
 				// Передаем фокус предыдущему полю
 				if m.step == 0 {
 					m.projectName.Focus()
@@ -118,27 +229,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "up", "down":
 			if m.step == 3 {
-				if m.projectType == "" {
-					m.projectType = "cli"
-				}
 				if msg.String() == "up" {
-					switch m.projectType {
-					case "cli":
-						m.projectType = "library"
-					case "web":
-						m.projectType = "cli"
-					case "library":
-						m.projectType = "web"
-					}
-				} else if msg.String() == "down" {
-					switch m.projectType {
-					case "cli":
-						m.projectType = "web"
-					case "web":
-						m.projectType = "library"
-					case "library":
-						m.projectType = "cli"
-					}
+					m.choice = (m.choice + len(m.choices) - 1) % len(m.choices)
+				} else {
+					m.choice = (m.choice + 1) % len(m.choices)
 				}
 				return m, nil
 			}
@@ -177,54 +271,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m *Model) createProject() tea.Cmd {
-	return func() tea.Msg {
-		projectName := strings.TrimSpace(m.projectName.Value())
-		if projectName == "" {
-			projectName = m.projectName.Placeholder
-		}
-
-		moduleName := strings.TrimSpace(m.moduleName.Value())
-		if moduleName == "" {
-			moduleName = projectName
-		}
-
-		directory := strings.TrimSpace(m.directory.Value())
-		if directory == "" {
-			directory = projectName
-		}
-
-		m.config = generator.Config{
-			ProjectName: projectName,
-			ModuleName:  moduleName,
-			Directory:   directory,
-			ProjectType: m.projectType,
-			InitVCS:     m.initVCS,
-		}
-
-		// Debug: print config values
-		fmt.Printf("TUI DEBUG: ProjectName=%s, ModuleName=%s, Directory=%s\n", projectName, moduleName, directory)
-
-		err := generator.InitProject(m.config)
-		if err != nil {
-			m.error = err
-		} else {
-			m.success = true
-		}
-		return nil
-	}
-}
-
 func (m Model) View() string {
 	if m.quitting {
 		return "" // Пустая строка, чтобы не мешать выводу success message
 	}
 
 	if m.step == 5 {
-		if m.error != nil {
-			return ErrorStyle.Render("❌ Error creating project: " + m.error.Error())
-		}
-		return SuccessStyle.Render("✅ Project created successfully!")
+		return m.progressView()
 	}
 
 	var b strings.Builder
@@ -254,24 +307,14 @@ func (m Model) View() string {
 	case 3:
 		b.WriteString(QuestionStyle.Render("What type of project do you want to create?"))
 		b.WriteString("\n\n")
-		if m.projectType == "" {
-			m.projectType = "cli"
-		}
-		if m.projectType == "cli" {
-			b.WriteString(SelectedStyle.Render("• CLI Application") + "\n")
-		} else {
-			b.WriteString(UnselectedStyle.Render("  CLI Application") + "\n")
-		}
-		if m.projectType == "web" {
-			b.WriteString(SelectedStyle.Render("• Web Application") + "\n")
-		} else {
-			b.WriteString(UnselectedStyle.Render("  Web Application") + "\n")
-		}
-		if m.projectType == "library" {
-			b.WriteString(SelectedStyle.Render("• Library") + "\n")
-		} else {
-			b.WriteString(UnselectedStyle.Render("  Library") + "\n")
+		for i, c := range m.choices {
+			if i == m.choice {
+				b.WriteString(SelectedStyle.Render("• "+c.title) + "\n")
+			} else {
+				b.WriteString(UnselectedStyle.Render("  "+c.title) + "\n")
+			}
 		}
+		b.WriteString("\n" + HelpStyle.Render(m.choices[m.choice].description))
 		b.WriteString(HelpStyle.Render("\n\nUse ↑/↓ to select, Enter to continue, Backspace to go back"))
 
 	case 4:
@@ -282,7 +325,12 @@ func (m Model) View() string {
 		} else {
 			b.WriteString(UnselectedStyle.Render("Yes") + "   " + SelectedStyle.Render("✓ No"))
 		}
-		b.WriteString(HelpStyle.Render("\n\nUse ←/→ or Y/N to toggle, Enter to create project"))
+		if m.violations != nil {
+			b.WriteString("\n" + ErrorStyle.Render(m.violations.Error()))
+			b.WriteString(HelpStyle.Render("\n\nPress Backspace to go back and change the project"))
+		} else {
+			b.WriteString(HelpStyle.Render("\n\nUse ←/→ or Y/N to toggle, Enter to create project"))
+		}
 	}
 
 	return b.String()
@@ -0,0 +1,290 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cardinalnsk/ginit/pkg/ginit"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	logWidth  = 80
+	logHeight = 10
+)
+
+type stepState int
+
+const (
+	stepPending stepState = iota
+	stepRunning
+	stepDone
+	stepFailed
+)
+
+type stepStatus struct {
+	title    string
+	state    stepState
+	duration time.Duration
+}
+
+// progressMsg доставляет событие генератора в цикл Bubble Tea
+type progressMsg ginit.Event
+
+// logMsg is a single formatted record from the generator logger
+type logMsg string
+
+// logWriter turns slog handler output into logMsg values. slog handlers
+// write exactly one record per Write call.
+type logWriter chan<- tea.Msg
+
+func (w logWriter) Write(p []byte) (int, error) {
+	w <- logMsg(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// finishedMsg is sent once the project is generated or generation failed
+type finishedMsg struct {
+	err error
+}
+
+// waitForProgress blocks until the generator goroutine sends the next message
+func waitForProgress(events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
+
+func (m Model) buildConfig() ginit.Config {
+	projectName := strings.TrimSpace(m.projectName.Value())
+	if projectName == "" {
+		projectName = m.projectName.Placeholder
+	}
+
+	directory := strings.TrimSpace(m.directory.Value())
+	if directory == "" {
+		directory = projectName
+	}
+
+	selected := m.choices[m.choice]
+	config := ginit.Config{
+		ProjectName: projectName,
+		ModuleName:  strings.TrimSpace(m.moduleName.Value()),
+		Directory:   directory,
+		ProjectType: selected.projectType,
+		Features:    m.features,
+		Variables:   m.variables,
+		InitVCS:     m.initVCS,
+	}
+	if selected.preset != nil {
+		// Тип из флага важнее типа пресета, как и без мастера
+		config.ProjectType = m.projectType
+		config = selected.preset.Apply(config)
+	}
+	if config.ModuleName == "" {
+		config.ModuleName = projectName
+	}
+	return config
+}
+
+// startProject runs the generator in the background and streams its
+// progress events back into the program through m.events.
+func (m *Model) startProject() tea.Cmd {
+	events := make(chan tea.Msg, 64)
+	m.events = events
+
+	g := ginit.New(
+		ginit.WithPolicy(m.policy),
+		ginit.WithReporter(ginit.ReporterFunc(func(e ginit.Event) {
+			events <- progressMsg(e)
+		})),
+		// Вывод команд и созданные файлы уже приходят через Reporter, поэтому
+		// от логгера нужны только предупреждения и ошибки
+		ginit.WithLogger(slog.New(slog.NewTextHandler(logWriter(events), &slog.HandlerOptions{
+			Level: max(m.logLevel, slog.LevelWarn),
+		}))),
+	)
+
+	plan, err := g.Plan(m.buildConfig())
+	if err != nil {
+		m.done = true
+		m.error = err
+		return nil
+	}
+	m.config = plan.Config
+
+	m.steps = nil
+	for _, title := range plan.Steps() {
+		m.steps = append(m.steps, stepStatus{title: title})
+	}
+	m.resizeLog()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	go func() {
+		defer cancel()
+		rendered, err := g.Render(plan)
+		if err == nil {
+			_, err = g.Apply(ctx, rendered)
+		}
+		events <- finishedMsg{err: err}
+	}()
+
+	return tea.Batch(m.spinner.Tick, waitForProgress(events))
+}
+
+func (m Model) updateProgress(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case progressMsg:
+		m.handleEvent(ginit.Event(msg))
+		return m, waitForProgress(m.events)
+
+	case logMsg:
+		m.appendLog(LogLineStyle.Render(string(msg)))
+		return m, waitForProgress(m.events)
+
+	case finishedMsg:
+		m.done = true
+		m.error = msg.err
+		m.success = msg.err == nil
+		if m.aborting {
+			// Генерация могла успеть завершиться до отмены
+			if msg.err != nil {
+				m.error = fmt.Errorf("generation aborted, %s may be incomplete", m.config.Directory)
+			}
+			m.quitting = true
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.done {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			// Команды go и git останавливаются, выходим после генератора
+			if !m.done && !m.aborting && m.cancel != nil {
+				m.aborting = true
+				m.cancel()
+				return m, nil
+			}
+			m.quitting = true
+			return m, tea.Quit
+		case "enter", "esc", "q":
+			if m.done {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+	}
+
+	// Остальное (прокрутка) отдаем логу
+	var cmd tea.Cmd
+	m.log, cmd = m.log.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) handleEvent(e ginit.Event) {
+	switch e.Kind {
+	case ginit.StepStarted:
+		m.setStepState(e.Step, stepRunning, 0)
+	case ginit.StepFinished:
+		m.setStepState(e.Step, stepDone, e.Duration)
+	case ginit.StepFailed:
+		m.setStepState(e.Step, stepFailed, e.Duration)
+		m.appendLog(StepFailedStyle.Render("✗ " + e.Step + ": " + e.Err.Error()))
+	case ginit.FileWritten:
+		m.appendLog(StepDoneStyle.Render("+ ") + e.Path)
+	case ginit.OutputLine:
+		m.appendLog(LogLineStyle.Render("  " + e.Line))
+	}
+}
+
+func (m *Model) setStepState(title string, state stepState, d time.Duration) {
+	for i := range m.steps {
+		if m.steps[i].title == title {
+			m.steps[i].state = state
+			m.steps[i].duration = d
+			return
+		}
+	}
+}
+
+func (m *Model) appendLog(line string) {
+	// Автопрокрутка только если пользователь не листает лог вверх
+	follow := m.log.AtBottom()
+	m.logLines = append(m.logLines, line)
+	m.log.SetContent(strings.Join(m.logLines, "\n"))
+	if follow {
+		m.log.GotoBottom()
+	}
+}
+
+// resizeLog fits the log viewport into the space left under the checklist
+func (m *Model) resizeLog() {
+	if m.width == 0 || m.height == 0 {
+		return
+	}
+	m.log.Width = m.width - 2
+	m.log.Height = max(m.height-len(m.steps)-12, 3)
+}
+
+func (m Model) progressView() string {
+	var b strings.Builder
+
+	b.WriteString(TitleStyle.Render("🚀 Go Project Initializer"))
+	b.WriteString("\n\n")
+	b.WriteString(QuestionStyle.Render("Creating " + m.config.ProjectName + "..."))
+	b.WriteString("\n\n")
+
+	for _, s := range m.steps {
+		switch s.state {
+		case stepPending:
+			b.WriteString(StepPendingStyle.Render("  ○ " + s.title))
+		case stepRunning:
+			b.WriteString("  " + m.spinner.View() + s.title)
+		case stepDone:
+			b.WriteString(StepDoneStyle.Render("  ✓ "+s.title) + HelpStyle.Render(" "+formatDuration(s.duration)))
+		case stepFailed:
+			b.WriteString(StepFailedStyle.Render("  ✗ "+s.title) + HelpStyle.Render(" "+formatDuration(s.duration)))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(LogStyle.Render(m.log.View()))
+	b.WriteString("\n")
+
+	switch {
+	case m.aborting:
+		b.WriteString(HelpStyle.Render("Aborting, waiting for running commands to stop..."))
+	case !m.done:
+		b.WriteString(HelpStyle.Render("Use ↑/↓ to scroll the log, Ctrl+C to abort"))
+	case m.error != nil:
+		b.WriteString(ErrorStyle.Render("❌ Error creating project: " + m.error.Error()))
+		b.WriteString(HelpStyle.Render("\nPress Enter to quit"))
+	default:
+		b.WriteString(SuccessStyle.Render("✅ Project created successfully!"))
+		b.WriteString(HelpStyle.Render("\nPress Enter to quit"))
+	}
+
+	return b.String()
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
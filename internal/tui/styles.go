@@ -61,6 +61,31 @@ var (
 
 	Tip = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("255"))
+
+	// Progress screen styles
+	SpinnerStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("39"))
+
+	StepPendingStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("240"))
+
+	StepDoneStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("42"))
+
+	WarningStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("214"))
+
+	StepFailedStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("196"))
+
+	LogStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("236"))
+
+	LogLineStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("250"))
 )
 
 // DefaultStyle возвращает стили для non-interactive режима
@@ -0,0 +1,356 @@
+package ginit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// commandOutputLines is how many trailing output lines a CommandError keeps
+const commandOutputLines = 20
+
+// applier holds the state of a single Apply run
+type applier struct {
+	ctx      context.Context
+	rendered *Rendered
+	config   Config
+	reporter Reporter
+	log      *slog.Logger
+	env      []string
+	step     string
+	result   *Result
+	files    []string
+}
+
+type step struct {
+	title string
+	run   func(a *applier) error
+}
+
+func applySteps(config Config) []step {
+	steps := []step{
+		{"Create project directory", (*applier).createDirectory},
+		{"Initialize Go module", (*applier).initGoMod},
+		{"Add dependencies", (*applier).addDependencies},
+		{"Create project structure", (*applier).createProjectStructure},
+		{"Write project files", (*applier).writeFiles},
+	}
+
+	if config.InitVCS {
+		steps = append(steps, step{"Initialize Git repository", (*applier).initVCS})
+	}
+
+	return steps
+}
+
+// Apply creates the project on disk: the directory, go.mod, dependencies,
+// the rendered files and the Git repository. The returned Result is never
+// nil: on failure it describes the steps that did run. Step failures are
+// returned as *StepError; a path that would be written through a symlink
+// out of the project directory is a *TemplateError, returned before
+// anything is written. Cancelling ctx stops the running go or git command
+// and skips the remaining steps.
+func (g *Generator) Apply(ctx context.Context, rendered *Rendered) (*Result, error) {
+	config := rendered.Plan.Config
+	a := &applier{
+		ctx:      ctx,
+		rendered: rendered,
+		config:   config,
+		reporter: g.reporter,
+		log:      g.log,
+		env:      g.env,
+		result:   newResult(config),
+	}
+	a.result.Features = append(a.result.Features, config.Features...)
+	a.result.NextSteps = append(a.result.NextSteps, rendered.Plan.NextSteps...)
+
+	start := time.Now()
+	defer func() { a.result.Timings.TotalMS = milliseconds(time.Since(start)) }()
+
+	if abs, err := filepath.Abs(config.Directory); err == nil {
+		a.result.Path = abs
+	}
+
+	a.log.Debug("generating project",
+		"dir", a.result.Path,
+		"project", config.ProjectName,
+		"module", config.ModuleName,
+		"type", config.ProjectType,
+		"features", config.Features,
+	)
+
+	// Ссылка наружу обнаруживается до записи go.mod и запуска go
+	paths := append([]string{"go.mod", "go.sum"}, rendered.Plan.Directories...)
+	if err := rendered.insideDir(config.Directory, append(paths, ManifestFile), rendered.Files); err != nil {
+		return a.result, err
+	}
+
+	for _, s := range applySteps(config) {
+		if err := ctx.Err(); err != nil {
+			return a.result, err
+		}
+		if err := a.runStep(s.title, s.run); err != nil {
+			return a.result, &StepError{Step: s.title, Err: err}
+		}
+	}
+
+	if err := a.result.collectFiles(config.Directory, a.files); err != nil {
+		return a.result, fmt.Errorf("failed to checksum generated files: %w", err)
+	}
+
+	return a.result, nil
+}
+
+// runStep runs fn and reports its start, result and duration
+func (a *applier) runStep(title string, fn func(a *applier) error) error {
+	a.step = title
+	defer func() { a.step = "" }()
+
+	a.report(Event{Kind: StepStarted})
+	a.log.Debug("step started", "step", title)
+	start := time.Now()
+
+	err := fn(a)
+	d := time.Since(start)
+	a.result.addStep(title, err, d)
+
+	if err != nil {
+		a.report(Event{Kind: StepFailed, Duration: d, Err: err})
+		// Ошибку показывает вызывающий, в логе она нужна только для -verbose
+		a.log.Debug("step failed", "step", title, "duration", d, "error", err)
+		return err
+	}
+
+	a.report(Event{Kind: StepFinished, Duration: d})
+	a.log.Debug("step finished", "step", title, "duration", d)
+	return nil
+}
+
+// warn logs a warning and records it in the result
+func (a *applier) warn(msg string) {
+	a.log.Warn(msg)
+	a.result.Warnings = append(a.result.Warnings, msg)
+}
+
+func (a *applier) report(e Event) {
+	if a.reporter == nil {
+		return
+	}
+	if e.Step == "" {
+		e.Step = a.step
+	}
+	a.reporter.Report(e)
+}
+
+// path converts a project-relative path into a path on disk
+func (a *applier) path(rel string) string {
+	return filepath.Join(a.config.Directory, filepath.FromSlash(rel))
+}
+
+func (a *applier) fileWritten(path string) {
+	a.files = append(a.files, path)
+	a.report(Event{Kind: FileWritten, Path: path})
+	a.log.Debug("file written", "path", path)
+}
+
+// runCommand runs an external command in the project directory, forwarding
+// its output line by line to the reporter and the logger.
+func (a *applier) runCommand(name string, args ...string) error {
+	cmd := exec.CommandContext(a.ctx, name, args...)
+	cmd.Dir = a.config.Directory
+	if len(a.env) > 0 {
+		cmd.Env = append(os.Environ(), a.env...)
+	}
+	a.log.Debug("running command", "cmd", cmd.String())
+
+	var tail []string
+	w := &lineWriter{emit: func(line string) {
+		a.report(Event{Kind: OutputLine, Line: line})
+		a.log.Debug(line, "cmd", name)
+
+		tail = append(tail, line)
+		if len(tail) > commandOutputLines {
+			tail = tail[1:]
+		}
+	}}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err := cmd.Run()
+	w.Flush()
+
+	if err != nil {
+		return &CommandError{Command: cmd.String(), Output: tail, Err: err}
+	}
+	return nil
+}
+
+func (a *applier) createDirectory() error {
+	if err := os.MkdirAll(a.config.Directory, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return nil
+}
+
+func (a *applier) initGoMod() error {
+	// Check if Go is available in PATH
+	if _, err := exec.LookPath("go"); err != nil {
+		// Go is not available, create go.mod file manually
+		a.warn("go not found in PATH, writing go.mod without go mod init")
+		goModContent := fmt.Sprintf("module %s\n\ngo %s\n", a.config.ModuleName, MinGoVersion)
+		if err := os.WriteFile(a.path("go.mod"), []byte(goModContent), 0644); err != nil {
+			return err
+		}
+		a.fileWritten("go.mod")
+		return nil
+	}
+
+	// Go is available, use go mod init
+	if err := a.runCommand("go", "mod", "init", a.config.ModuleName); err != nil {
+		return err
+	}
+
+	a.fileWritten("go.mod")
+	return nil
+}
+
+func (a *applier) addDependencies() error {
+	dependencies := a.rendered.Plan.Dependencies
+	if len(dependencies) == 0 {
+		return nil
+	}
+
+	// Check if Go is available in PATH
+	if _, err := exec.LookPath("go"); err != nil {
+		a.warn("go not found in PATH, skipping dependency installation")
+		return nil
+	}
+
+	for _, dep := range dependencies {
+		if err := a.runCommand("go", "get", dep); err != nil {
+			return fmt.Errorf("failed to add dependency %s: %w", dep, err)
+		}
+	}
+
+	a.fileWritten("go.sum")
+
+	deps, err := requiredVersions(a.path("go.mod"), dependencies)
+	if err != nil {
+		return fmt.Errorf("failed to read dependency versions: %w", err)
+	}
+	a.result.Dependencies = append(a.result.Dependencies, deps...)
+
+	return nil
+}
+
+func (a *applier) createProjectStructure() error {
+	for _, dir := range a.rendered.Plan.Directories {
+		if err := os.MkdirAll(a.path(dir), 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+func (a *applier) writeFiles() error {
+	for _, f := range a.rendered.Files {
+		path := a.path(f.Path)
+
+		// Создаем директорию если нужно
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, f.Content, 0644); err != nil {
+			return err
+		}
+		a.fileWritten(f.Path)
+	}
+
+	if err := os.WriteFile(a.path(ManifestFile), newManifest(a.rendered).encode(), 0644); err != nil {
+		return err
+	}
+	a.fileWritten(ManifestFile)
+	return nil
+}
+
+func (a *applier) initVCS() error {
+	if _, err := exec.LookPath("git"); err != nil {
+		a.warn("git not found in PATH, skipping VCS initialization")
+		a.result.VCS = VCSResult{Status: VCSSkipped, Reason: "git not found in PATH"}
+		return nil
+	}
+
+	if err := a.runCommand("git", "init"); err != nil {
+		return fmt.Errorf("failed to init git: %w", err)
+	}
+
+	a.result.VCS = VCSResult{Status: VCSInitialized}
+	return nil
+}
+
+// insideDir returns an error if writing the slash-separated path rel below
+// dir would follow a symlink out of dir, e.g. an existing internal/ linked
+// to /etc. Symlinks that stay inside dir are followed; elements that do
+// not exist yet are created as directories and files.
+func insideDir(dir, rel string) error {
+	root, err := filepath.EvalSymlinks(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	path := dir
+	for _, elem := range strings.Split(rel, "/") {
+		path = filepath.Join(path, elem)
+		info, err := os.Lstat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			continue
+		}
+
+		link, _ := os.Readlink(path)
+		// Запись через висящую ссылку создала бы ее цель, где бы та ни была
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return fmt.Errorf("%s is a symlink to %s, which does not exist", filepath.ToSlash(path), link)
+		}
+		if !within(root, target) {
+			return fmt.Errorf("%s is a symlink to %s, outside %s", filepath.ToSlash(path), link, dir)
+		}
+	}
+	return nil
+}
+
+// insideDir checks the paths and the files that are about to be written
+// below dir with insideDir before anything is, so that a symlink out of
+// dir leaves the project untouched. The error is a *TemplateError.
+func (r *Rendered) insideDir(dir string, paths []string, files []File) error {
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	for _, p := range paths {
+		if err := insideDir(dir, strings.TrimSuffix(p, "/")); err != nil {
+			return r.pathError(p, err)
+		}
+	}
+	return nil
+}
+
+// within reports whether the resolved path target is root or below it
+func within(root, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,178 @@
+package ginit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInvalidPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"cmd/app/main.go", ""},
+		{".gitignore", ""},
+		{"", "is empty"},
+		{"/etc/passwd", "is absolute"},
+		{"C:/Windows/win.ini", "is absolute"},
+		{`internal\config.go`, "backslash"},
+		{"internal/", "names a directory"},
+		{"internal//config.go", "empty element"},
+		{"../outside.go", ". or .."},
+		{"internal/../../outside.go", ". or .."},
+		{"./main.go", ". or .."},
+		{"..", ". or .."},
+	}
+	for _, tt := range tests {
+		got := invalidPath(tt.path)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("invalidPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// symlinkTree creates a project directory and a directory outside it,
+// both with a file, and the symlinks below the project
+func symlinkTree(t *testing.T, links map[string]string) (dir, outside string) {
+	t.Helper()
+	parent := t.TempDir()
+	dir, outside = filepath.Join(parent, "project"), filepath.Join(parent, "outside")
+	writeFiles(t, dir, map[string]string{"internal/config/config.go": "package config\n"})
+	writeFiles(t, outside, map[string]string{"passwd": "root\n"})
+	for link, target := range links {
+		target = strings.ReplaceAll(target, "$OUTSIDE", outside)
+		if err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(link))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, outside
+}
+
+func TestInsideDir(t *testing.T) {
+	tests := []struct {
+		name  string
+		links map[string]string
+		path  string
+		err   string
+	}{
+		{name: "new file", path: "cmd/app/main.go"},
+		{name: "existing file", path: "internal/config/config.go"},
+		{name: "symlink inside", links: map[string]string{"pkg": "internal"}, path: "pkg/config/config.go"},
+		{name: "file symlink inside", links: map[string]string{"go.mod": "internal/config/config.go"}, path: "go.mod"},
+		{name: "directory symlink outside", links: map[string]string{"cmd": "$OUTSIDE"}, path: "cmd/app/main.go", err: "outside"},
+		{name: "relative symlink outside", links: map[string]string{"cmd": "../outside"}, path: "cmd/app/main.go", err: "outside"},
+		{name: "file symlink outside", links: map[string]string{"go.mod": "$OUTSIDE/passwd"}, path: "go.mod", err: "outside"},
+		{name: "nested symlink outside", links: map[string]string{"internal/config/db": "$OUTSIDE"}, path: "internal/config/db/db.go", err: "outside"},
+		{name: "dangling symlink", links: map[string]string{"go.sum": "$OUTSIDE/go.sum"}, path: "go.sum", err: "does not exist"},
+		{name: "directory", links: map[string]string{"docs": "$OUTSIDE"}, path: "docs", err: "outside"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, _ := symlinkTree(t, tt.links)
+			err := insideDir(dir, tt.path)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("insideDir(%q): %v", tt.path, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("insideDir(%q) = %v, want an error containing %q", tt.path, err, tt.err)
+			}
+		})
+	}
+
+	if err := insideDir(filepath.Join(t.TempDir(), "missing"), "cmd/app/main.go"); err != nil {
+		t.Errorf("insideDir of a missing directory: %v", err)
+	}
+}
+
+func TestPackLink(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		err    string
+	}{
+		{name: "file inside", target: "../shared/header.tmpl"},
+		{name: "relative outside", target: "../../outside/passwd", err: "outside the pack"},
+		{name: "absolute outside", target: "$OUTSIDE/passwd", err: "outside the pack"},
+		{name: "dangling", target: "missing.tmpl", err: "does not exist"},
+		{name: "directory", target: "../shared", err: "symlink to a directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dir, outside := filepath.Join(parent, "pack"), filepath.Join(parent, "outside")
+			writeFiles(t, dir, map[string]string{"shared/header.tmpl": "header", "files/main.go.tmpl": "package main\n"})
+			writeFiles(t, outside, map[string]string{"passwd": "root\n"})
+			link := filepath.Join(dir, "files", "link.tmpl")
+			if err := os.Symlink(strings.ReplaceAll(tt.target, "$OUTSIDE", outside), link); err != nil {
+				t.Fatal(err)
+			}
+
+			err := packLink(dir, link)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("packLink: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("packLink = %v, want an error containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestApplyChecksPathsBeforeWriting(t *testing.T) {
+	tests := []struct {
+		name  string
+		links map[string]string
+		path  string
+	}{
+		{name: "rendered file", links: map[string]string{"cmd": "$OUTSIDE"}, path: "cmd/app/main.go"},
+		{name: "directory", links: map[string]string{"docs": "$OUTSIDE"}, path: "docs"},
+		{name: "go.mod", links: map[string]string{"go.mod": "$OUTSIDE/passwd"}, path: "go.mod"},
+		{name: "manifest", links: map[string]string{ManifestFile: "$OUTSIDE/passwd"}, path: ManifestFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, outside := symlinkTree(t, tt.links)
+			rendered := &Rendered{
+				Plan: &Plan{
+					Config:      Config{ProjectName: "app", ModuleName: "example.com/app", Directory: dir, ProjectType: "cli"},
+					Directories: []string{"docs"},
+					Files:       []PlannedFile{{Path: "cmd/app/main.go", Template: "cli/main.go"}},
+				},
+				Files: []File{{Path: "cmd/app/main.go", Content: []byte("package main\n")}},
+			}
+
+			result, err := New().Apply(context.Background(), rendered)
+			var te *TemplateError
+			if !errors.As(err, &te) {
+				t.Fatalf("Apply = %v, want a TemplateError", err)
+			}
+			if te.Path != tt.path {
+				t.Errorf("error path = %q, want %q", te.Path, tt.path)
+			}
+			if result == nil || len(result.Timings.Steps) > 0 {
+				t.Errorf("steps ran: %+v", result)
+			}
+
+			if _, err := os.Lstat(filepath.Join(dir, "go.mod")); err == nil && tt.path != "go.mod" {
+				t.Error("go.mod was written")
+			}
+			entries, err := os.ReadDir(outside)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("files were written outside the project: %v", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package ginit
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// checkGo parses and type-checks the rendered .go files of a project whose
+// module is module. Standard library imports are checked against the
+// installed Go, packages of the project against the rendered files, and
+// any other import is replaced by an empty stub, so uses of third-party
+// packages are not verified. Without an installed Go the standard library
+// is stubbed as well, see stdlibAvailable. The returned error joins a
+// *CompileError per problem.
+func checkGo(module string, files []File) error {
+	fset := token.NewFileSet()
+	packages := make(map[string][]*ast.File)
+	var errs []error
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, ".go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, f.Path, f.Content, parser.SkipObjectResolution)
+		if err != nil {
+			var list scanner.ErrorList
+			if errors.As(err, &list) {
+				for _, e := range list {
+					errs = append(errs, compileError(e.Pos, e.Msg))
+				}
+				continue
+			}
+			return err
+		}
+		dir := path.Dir(f.Path)
+		packages[dir] = append(packages[dir], file)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	c := &checker{
+		module:   module,
+		fset:     fset,
+		packages: packages,
+		checked:  make(map[string]*types.Package),
+		std:      importer.Default(),
+	}
+
+	dirs := make([]string, 0, len(packages))
+	for dir := range packages {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		c.check(dir)
+	}
+	return errors.Join(c.errs...)
+}
+
+// checker type-checks the packages of a rendered project, each once, in
+// import order
+type checker struct {
+	module   string
+	fset     *token.FileSet
+	packages map[string][]*ast.File
+	checked  map[string]*types.Package
+	std      types.Importer
+	errs     []error
+}
+
+func (c *checker) check(dir string) *types.Package {
+	if pkg, ok := c.checked[dir]; ok {
+		return pkg
+	}
+	// Пакет регистрируем до проверки, чтобы циклический импорт не
+	// приводил к бесконечной рекурсии
+	c.checked[dir] = nil
+
+	files := c.packages[dir]
+	stubs := make(map[string]bool)
+	local := localNames(files)
+	conf := types.Config{
+		Importer: importerFunc(func(importPath string) (*types.Package, error) {
+			return c.importPackage(importPath, stubs)
+		}),
+		Error: func(err error) {
+			te, ok := err.(types.Error)
+			if !ok {
+				c.errs = append(c.errs, err)
+				return
+			}
+			if stubbed(te.Msg, local, stubs) {
+				return
+			}
+			c.errs = append(c.errs, compileError(te.Fset.Position(te.Pos), te.Msg))
+		},
+	}
+
+	pkg, _ := conf.Check(path.Join(c.module, dir), c.fset, files, nil)
+	c.checked[dir] = pkg
+	return pkg
+}
+
+func (c *checker) importPackage(importPath string, stubs map[string]bool) (*types.Package, error) {
+	if importPath == c.module || strings.HasPrefix(importPath, c.module+"/") {
+		dir := strings.TrimPrefix(strings.TrimPrefix(importPath, c.module), "/")
+		if dir == "" {
+			dir = "."
+		}
+		if _, ok := c.packages[dir]; !ok {
+			return nil, fmt.Errorf("package %s is not generated", importPath)
+		}
+		if pkg := c.check(dir); pkg != nil {
+			return pkg, nil
+		}
+		return nil, fmt.Errorf("import cycle through %s", importPath)
+	}
+
+	if isStdlib(importPath) && stdlibAvailable() {
+		return c.std.Import(importPath)
+	}
+
+	pkg := types.NewPackage(importPath, stubName(importPath))
+	pkg.MarkComplete()
+	stubs[importPath] = true
+	return pkg, nil
+}
+
+// stubbed reports whether msg is about a name looked up in a stub package.
+// local maps the names packages are imported under to their import paths,
+// stubs holds the import paths of the stub packages.
+func stubbed(msg string, local map[string]string, stubs map[string]bool) bool {
+	m := undefinedSelector.FindStringSubmatch(msg)
+	return m != nil && stubs[local[m[1]]]
+}
+
+// localNames maps the names files refer to their imports by, such as
+// healthpb for a renamed import, to the import paths
+func localNames(files []*ast.File) map[string]string {
+	names := make(map[string]string)
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			importPath, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			name := stubName(importPath)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			names[name] = importPath
+		}
+	}
+	return names
+}
+
+var undefinedSelector = regexp.MustCompile(`^undefined: (\w+)\.\w+$`)
+
+// stdlibAvailable reports whether the export data of the standard library
+// can be loaded. It comes from the go command, which need not be
+// installed: ginit then writes go.mod itself, see initGoMod.
+var stdlibAvailable = sync.OnceValue(func() bool {
+	_, err := importer.Default().Import("errors")
+	return err == nil
+})
+
+// isStdlib reports whether importPath belongs to the standard library,
+// whose first path element never contains a dot
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// stubName guesses the package name of a third-party import path from the
+// usual conventions: the last element without a major version suffix and a
+// "go-" prefix, e.g. redis for github.com/redis/go-redis/v9
+func stubName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersion.MatchString(name) {
+		name = elems[len(elems)-2]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	return strings.NewReplacer("-", "_", ".", "_").Replace(name)
+}
+
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+func compileError(pos token.Position, msg string) *CompileError {
+	return &CompileError{Path: pos.Filename, Line: pos.Line, Column: pos.Column, Msg: msg}
+}
@@ -0,0 +1,186 @@
+package ginit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrTemplateNotFound is wrapped by TemplateError when a Plan refers to a
+// template that is not in the registry
+var ErrTemplateNotFound = errors.New("template not found")
+
+// ConfigError reports an invalid Config field
+type ConfigError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// PolicyError reports a project that does not comply with a Policy
+type PolicyError struct {
+	Policy string
+	// Violations explain the broken rules, one per rule
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	name := "policy"
+	if e.Policy != "" {
+		name = "policy " + e.Policy
+	}
+	return fmt.Sprintf("project does not comply with %s:\n  - %s", name, strings.Join(e.Violations, "\n  - "))
+}
+
+// IntegrityError reports a template pack that does not match its recorded
+// checksums or is not signed by a trusted key. Such a pack is not used.
+type IntegrityError struct {
+	Pack   string
+	Reason string
+	// Files lists the changed, added and removed files, if known
+	Files []string
+}
+
+func (e *IntegrityError) Error() string {
+	msg := fmt.Sprintf("template pack %s failed verification: %s", e.Pack, e.Reason)
+	if len(e.Files) > 0 {
+		msg += "\n  - " + strings.Join(e.Files, "\n  - ")
+	}
+	return msg
+}
+
+// ConflictError reports files AddFeatures would have to change that were
+// modified since the project was generated. Nothing is written then, as
+// the project would no longer build with only part of a feature.
+type ConflictError struct {
+	Paths []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("files were modified locally, nothing was written:\n  - %s", strings.Join(e.Paths, "\n  - "))
+}
+
+// TemplateError reports a template that could not be found, parsed or
+// executed, or a project path it cannot write. Path is the project file
+// being rendered, if any; Name is empty for paths no template renders,
+// such as directories and go.mod. Line and Column locate a parse or
+// execution error in the template source when known; Column is 0 for
+// parse errors, which text/template reports per line. Snippet is the
+// offending source line.
+type TemplateError struct {
+	Name    string
+	Path    string
+	Line    int
+	Column  int
+	Snippet string
+	Err     error
+}
+
+func (e *TemplateError) Error() string {
+	name := e.Name
+	if e.Line > 0 {
+		name += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			name += ":" + strconv.Itoa(e.Column)
+		}
+	}
+
+	msg := fmt.Sprintf("template %s: %s", name, e.message())
+	switch {
+	case e.Name == "" && e.Path != "":
+		msg = fmt.Sprintf("path %s: %s", e.Path, e.message())
+	case e.Path != "":
+		msg = fmt.Sprintf("template %s (rendering %s): %s", name, e.Path, e.message())
+	}
+	if e.Snippet != "" {
+		msg += "\n    " + e.Snippet
+		if e.Column > 0 {
+			msg += "\n    " + caret(e.Snippet, e.Column)
+		}
+	}
+	return msg
+}
+
+// message is the error without the position text/template prefixes it
+// with, which Error reports itself
+func (e *TemplateError) message() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	}
+	if m := templateErrorPos.FindStringSubmatch(e.Err.Error()); m != nil {
+		return m[4]
+	}
+	return e.Err.Error()
+}
+
+// caret returns a line pointing at column of line, keeping its tabs so
+// that the caret lines up
+func caret(line string, column int) string {
+	var b strings.Builder
+	for i, r := range line {
+		if i >= column-1 {
+			break
+		}
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String() + "^"
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// CompileError reports a generated Go file that does not parse or
+// type-check. Path is relative to the project directory.
+type CompileError struct {
+	Path   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Msg)
+}
+
+// StepError wraps the failure of a single Apply step
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// CommandError reports a failed external command (go, git). Output holds
+// the last lines the command printed.
+type CommandError struct {
+	Command string
+	Output  []string
+	Err     error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Command, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
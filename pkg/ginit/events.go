@@ -0,0 +1,97 @@
+package ginit
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventKind описывает тип события прогресса генерации
+type EventKind int
+
+const (
+	StepStarted EventKind = iota
+	StepFinished
+	StepFailed
+	FileWritten
+	OutputLine
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case StepStarted:
+		return "step_started"
+	case StepFinished:
+		return "step_finished"
+	case StepFailed:
+		return "step_failed"
+	case FileWritten:
+		return "file_written"
+	case OutputLine:
+		return "output_line"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a single progress notification emitted by Apply.
+// Step is set for every event, Path only for FileWritten, Line only for
+// OutputLine, Duration for StepFinished/StepFailed and Err for StepFailed.
+type Event struct {
+	Kind     EventKind
+	Step     string
+	Path     string
+	Line     string
+	Duration time.Duration
+	Err      error
+}
+
+// Reporter receives progress events. Report is called synchronously from
+// the generator, so implementations should not block for long.
+type Reporter interface {
+	Report(Event)
+}
+
+// ReporterFunc adapts a plain function to the Reporter interface
+type ReporterFunc func(Event)
+
+func (f ReporterFunc) Report(e Event) {
+	f(e)
+}
+
+// lineWriter splits subprocess output into lines and passes each of them to
+// emit. Stdout and stderr share one writer, hence the mutex.
+type lineWriter struct {
+	mu   sync.Mutex
+	emit func(line string)
+	buf  bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Неполная строка - ждем продолжения
+			w.buf.WriteString(line)
+			break
+		}
+		w.emit(strings.TrimRight(line, "\r\n"))
+	}
+	return len(p), nil
+}
+
+// Flush emits whatever is left in the buffer without a trailing newline
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.emit(strings.TrimRight(w.buf.String(), "\r"))
+		w.buf.Reset()
+	}
+}
@@ -0,0 +1,305 @@
+package ginit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ExtractPack turns the Go project in dir into a template pack: the module
+// path, the project name and the identifiers derived from it, e.g.
+// MyApp, myApp, my_app and MY_APP for my-app, are replaced by template
+// expressions in every file and path. Existing template delimiters are
+// escaped. The project name is taken from .ginit.json, or else the last
+// element of the module path. Binary files, .git and .ginit.json are
+// skipped; the skipped paths are returned alongside the pack.
+func ExtractPack(dir string) (*Pack, []string, error) {
+	module, goVersion, err := readGoMod(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read go.mod: %w", err)
+	}
+	if module == "" {
+		return nil, nil, fmt.Errorf("go.mod in %s has no module directive", dir)
+	}
+
+	name := moduleBase(module)
+	if m, err := ReadManifest(dir); err == nil && m.Project != "" {
+		name = m.Project
+	}
+
+	p := &Pack{
+		Manifest: PackManifest{
+			Name:        name,
+			Description: "Extracted from " + module,
+			GoVersion:   goVersion,
+		},
+	}
+	r := newExtractor(module, name)
+
+	var skipped []string
+	err = filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			// Ранее извлеченный пакет внутри проекта не является его частью
+			if _, err := os.Stat(filepath.Join(file, PackManifestFile)); err == nil {
+				skipped = append(skipped, rel+"/")
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == ManifestFile {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			skipped = append(skipped, rel)
+			return nil
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0 {
+			skipped = append(skipped, rel)
+			return nil
+		}
+
+		templated := r.replace(rel)
+		p.Files = append(p.Files, PackFile{
+			Path:    templated,
+			Source:  templated + ".tmpl",
+			Content: r.replace(string(content)),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, skipped, nil
+}
+
+// Write saves the pack to dir, which must not exist or be empty
+func (p *Pack) Write(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("directory %s is not empty", dir)
+	}
+
+	manifest, err := json.MarshalIndent(p.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, PackManifestFile), append(manifest, '\n'), 0644); err != nil {
+		return err
+	}
+
+	for _, files := range []struct {
+		dir   string
+		files []PackFile
+	}{
+		{PackFilesDir, p.Files},
+		{PackPartialsDir, p.Partials},
+	} {
+		for _, f := range files.files {
+			file := filepath.Join(dir, files.dir, filepath.FromSlash(f.Source))
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(file, []byte(f.Content), 0644); err != nil {
+				return err
+			}
+		}
+	}
+	p.Dir = dir
+	return nil
+}
+
+// readGoMod returns the module path and the go version of a go.mod file
+func readGoMod(file string) (module, goVersion string, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			module = strings.Trim(fields[1], `"`)
+		case "go":
+			goVersion = fields[1]
+		}
+	}
+	return module, goVersion, scanner.Err()
+}
+
+// moduleBase returns the last element of a module path that is not a
+// major version suffix, e.g. app for example.com/app/v2
+func moduleBase(module string) string {
+	base := path.Base(module)
+	if majorVersion.MatchString(base) && path.Dir(module) != "." {
+		base = path.Base(path.Dir(module))
+	}
+	return base
+}
+
+// extractor replaces the module path and the spellings of the project name
+// by template expressions
+type extractor struct {
+	module       string
+	replacements []replacement
+}
+
+type replacement struct {
+	text   string
+	expr   string
+	module bool
+}
+
+func newExtractor(module, name string) *extractor {
+	r := &extractor{module: module}
+	seen := make(map[string]bool)
+	add := func(text, expr string, module bool) {
+		if text == "" || seen[text] {
+			return
+		}
+		seen[text] = true
+		r.replacements = append(r.replacements, replacement{text, "{{" + expr + "}}", module})
+	}
+
+	add(module, ".Module", true)
+	add(name, ".ProjectName", false)
+	add(pascalCase(name), "pascal .ProjectName", false)
+	add(camelCase(name), "camel .ProjectName", false)
+	add(joinWords(name, "_"), "snake .ProjectName", false)
+	add(joinWords(name, "-"), "kebab .ProjectName", false)
+	add(strings.ToUpper(joinWords(name, "_")), "upper (snake .ProjectName)", false)
+
+	// Длинные совпадения важнее: модуль содержит имя проекта
+	sort.SliceStable(r.replacements, func(i, j int) bool {
+		return len(r.replacements[i].text) > len(r.replacements[j].text)
+	})
+	return r
+}
+
+// replace templates s in a single pass, so that inserted expressions are
+// never replaced again
+func (r *extractor) replace(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "{{"):
+			b.WriteString(`{{"{{"}}`)
+			i += 2
+			continue
+		case strings.HasPrefix(s[i:], "}}"):
+			b.WriteString(`{{"}}"}}`)
+			i += 2
+			continue
+		}
+
+		matched := false
+		for _, rep := range r.replacements {
+			if strings.HasPrefix(s[i:], rep.text) && rep.matchesAt(s, i) && (rep.module || !r.foreignPath(s, i)) {
+				b.WriteString(rep.expr)
+				i += len(rep.text)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// matchesAt reports whether the occurrence of rep at s[i:] is a whole
+// word: a module path must not continue with a path character, a name
+// must not be part of another word, except at a camel case boundary as
+// in NewMyAppServer
+func (rep replacement) matchesAt(s string, i int) bool {
+	end := i + len(rep.text)
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, _ := utf8.DecodeRuneInString(s[end:])
+	if i == 0 {
+		before = utf8.RuneError
+	}
+	if end == len(s) {
+		after = utf8.RuneError
+	}
+
+	if rep.module {
+		return !wordRune(after) && after != '-'
+	}
+
+	first, _ := utf8.DecodeRuneInString(rep.text)
+	last, _ := utf8.DecodeLastRuneInString(rep.text)
+	startOK := !wordRune(before) || before == '_' ||
+		(unicode.IsUpper(first) && (unicode.IsLower(before) || unicode.IsDigit(before)))
+	endOK := !wordRune(after) || after == '_' ||
+		(unicode.IsUpper(after) && !unicode.IsUpper(last))
+	return startOK && endOK
+}
+
+// foreignPath reports whether s[i] is inside the import path of another
+// module, e.g. github.com/redis/go-redis for a project named redis, which
+// must be kept as is
+func (r *extractor) foreignPath(s string, i int) bool {
+	start := strings.LastIndexFunc(s[:i], pathDelimiter) + 1
+	end := len(s)
+	if n := strings.IndexFunc(s[i:], pathDelimiter); n >= 0 {
+		end = i + n
+	}
+	token := s[start:end]
+
+	first, _, ok := strings.Cut(token, "/")
+	// Путь модуля начинается с домена, ./cmd/app - локальный путь
+	if !ok || !strings.Contains(first, ".") || strings.HasPrefix(first, ".") {
+		return false
+	}
+	return token != r.module && !strings.HasPrefix(token, r.module+"/")
+}
+
+// pathDelimiter reports whether r ends an import path in source text
+func pathDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("\"'`()[],;=<>", r)
+}
+
+// wordRune reports whether r can be part of an identifier
+func wordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
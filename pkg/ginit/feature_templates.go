@@ -0,0 +1,338 @@
+package ginit
+
+// Шаблоны файлов, которые добавляют встроенные фичи
+
+const dockerfileTemplate = `# syntax=docker/dockerfile:1
+
+FROM golang:1-alpine AS build
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -trimpath -ldflags="-s -w" -o /out/{{.ProjectName}} ./cmd/{{.ProjectName}}
+
+FROM gcr.io/distroless/static-debian12:nonroot
+COPY --from=build /out/{{.ProjectName}} /{{.ProjectName}}
+{{- if eq .Type "web"}}
+EXPOSE 8080
+{{- else if eq .Type "grpc"}}
+EXPOSE 50051
+{{- end}}
+{{- if .HasFeature "metrics"}}
+EXPOSE 9090
+{{- end}}
+ENTRYPOINT ["/{{.ProjectName}}"]
+`
+
+const dockerignoreTemplate = `.git
+bin/
+Dockerfile
+.dockerignore
+`
+
+const githubCITemplate = `name: CI
+
+on:
+  push:
+    branches: [main]
+  pull_request:
+
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+      - run: go vet ./...
+      - run: go test -race ./...
+      - run: go build ./...
+{{- if .HasFeature "lint"}}
+
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+      - uses: golangci/golangci-lint-action@v8
+        with:
+          version: latest
+{{- end}}
+`
+
+const gitlabCITemplate = `image: golang:1
+
+stages:
+  - test
+
+test:
+  stage: test
+  script:
+    - go vet ./...
+    - go test -race ./...
+    - go build ./...
+{{- if .HasFeature "lint"}}
+
+lint:
+  stage: test
+  image: golangci/golangci-lint:latest
+  script:
+    - golangci-lint run
+{{- end}}
+`
+
+const golangciTemplate = `version: "2"
+
+linters:
+  enable:
+    - errorlint
+    - gocritic
+    - misspell
+    - revive
+    - unconvert
+
+formatters:
+  enable:
+    - gofmt
+    - goimports
+  settings:
+    goimports:
+      local-prefixes:
+        - {{.Module}}
+`
+
+const licenseTemplate = `MIT License
+
+Copyright (c) The {{.ProjectName}} Authors
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+const metricsTemplate = `package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Requests counts handled requests by result
+var Requests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "{{snake .ProjectName}}",
+	Name:      "requests_total",
+	Help:      "Handled requests by result.",
+}, []string{"result"})
+
+// Serve exposes /metrics on addr until ctx is done
+func Serve(ctx context.Context, addr string, log *slog.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.ErrorContext(ctx, "Metrics server failed", "error", err)
+	}
+}
+`
+
+const databaseTemplate = `package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Open connects to PostgreSQL and checks the connection
+func Open(ctx context.Context, url string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	db.SetMaxOpenConns(10)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping database: %w", err)
+	}
+	return db, nil
+}
+`
+
+const migrateTemplate = `package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies the migrations in migrations/ that were not applied yet,
+// in file name order
+func Migrate(ctx context.Context, db *sql.DB) error {
+	const create = "CREATE TABLE IF NOT EXISTS schema_migrations (name text PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())"
+	if _, err := db.ExecContext(ctx, create); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var applied bool
+		err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE name = $1)", name).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("check %s: %w", name, err)
+		}
+		if applied {
+			continue
+		}
+
+		if err := apply(ctx, db, name); err != nil {
+			return fmt.Errorf("apply %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, db *sql.DB, name string) error {
+	query, err := migrations.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(query)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (name) VALUES ($1)", name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+`
+
+const initMigrationTemplate = `-- Initial schema of {{.ProjectName}}
+CREATE TABLE IF NOT EXISTS examples (
+	id         bigserial PRIMARY KEY,
+	name       text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+`
+
+const redisTemplate = `package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedis connects to Redis at url (redis://host:port/db) and checks the
+// connection
+func NewRedis(ctx context.Context, url string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("ping redis: %w", err)
+	}
+	return client, nil
+}
+`
+
+const authTemplate = `package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type claimsKey struct{}
+
+// Auth rejects requests without a valid HS256 bearer token signed with
+// secret
+func Auth(secret string, next http.Handler) http.Handler {
+	key := []byte(secret)
+	keyFunc := func(*jwt.Token) (any, error) { return key, nil }
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		claims := jwt.MapClaims{}
+		if _, err := jwt.ParseWithClaims(raw, claims, keyFunc, jwt.WithValidMethods([]string{"HS256"})); err != nil {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+	})
+}
+
+// Claims returns the token claims of an authenticated request
+func Claims(ctx context.Context) (jwt.MapClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(jwt.MapClaims)
+	return claims, ok
+}
+`
@@ -0,0 +1,269 @@
+package ginit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Snippet points are the places in the built-in templates where features
+// insert code
+const (
+	// SnippetMainSetup is in main() after the config and logger are created;
+	// ctx, cfg and log are in scope and the code may call os.Exit
+	SnippetMainSetup = "main.setup"
+	// SnippetAppHandler is in App.Run of web projects; handler is an
+	// http.Handler that may be wrapped, a is the *App
+	SnippetAppHandler = "app.handler"
+)
+
+// Feature is an optional capability, such as a Dockerfile or a database
+// connection, layered on top of a project type. Implement it and register
+// it with FeatureRegistry.Register to add a feature.
+type Feature interface {
+	Info() FeatureInfo
+	// Contribute returns what the feature adds to a project of config
+	Contribute(config Config) Contribution
+}
+
+// FeatureInfo describes a feature and its relations to other features
+type FeatureInfo struct {
+	Name        string
+	Description string
+	// Types lists the project types the feature supports, all if empty
+	Types []string
+	// Requires lists features that are added together with this one
+	Requires []string
+	// Conflicts lists features that cannot be used together with this one
+	Conflicts []string
+}
+
+// Contribution is what a feature adds to a project
+type Contribution struct {
+	Directories  []string
+	Files        []PlannedFile
+	Dependencies []string
+	// ConfigFields are added to the Config struct of internal/config
+	ConfigFields []ConfigField
+	Snippets     []Snippet
+	NextSteps    []string
+	// ReadmeSections are appended to the generated README.md
+	ReadmeSections []ReadmeSection
+}
+
+// ConfigField is a field of the generated Config struct
+type ConfigField struct {
+	Name string
+	// Type is a Go type, e.g. "string"
+	Type string
+	// Default is a Go expression assigned in config.Load
+	Default string
+	Comment string
+}
+
+// Snippet is Go code inserted into a template at a snippet point
+type Snippet struct {
+	Point string
+	// Imports are the import paths the code needs
+	Imports []string
+	// Code is inserted as is, indented for the function body
+	Code string
+}
+
+// FeatureRegistry holds the features a Generator can add to projects
+type FeatureRegistry struct {
+	mu       sync.RWMutex
+	features []Feature
+}
+
+// NewFeatureRegistry returns an empty feature registry
+func NewFeatureRegistry() *FeatureRegistry {
+	return &FeatureRegistry{}
+}
+
+// DefaultFeatures returns a new registry with the built-in features
+func DefaultFeatures() *FeatureRegistry {
+	r := NewFeatureRegistry()
+	r.features = append(r.features, builtinFeatures()...)
+	return r
+}
+
+// Register adds a feature or replaces the one with the same name. Features
+// are applied in registration order, so a feature should be registered
+// after the features it requires.
+func (r *FeatureRegistry) Register(f Feature) error {
+	name := f.Info().Name
+	if name == "" {
+		return errors.New("feature name must not be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.features {
+		if r.features[i].Info().Name == name {
+			r.features[i] = f
+			return nil
+		}
+	}
+	r.features = append(r.features, f)
+	return nil
+}
+
+// Lookup returns the named feature
+func (r *FeatureRegistry) Lookup(name string) (Feature, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, f := range r.features {
+		if f.Info().Name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// List describes the registered features in registration order
+func (r *FeatureRegistry) List() []FeatureInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]FeatureInfo, 0, len(r.features))
+	for _, f := range r.features {
+		infos = append(infos, f.Info())
+	}
+	return infos
+}
+
+// Names returns the names of the registered features in registration order
+func (r *FeatureRegistry) Names() []string {
+	var names []string
+	for _, f := range r.List() {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Resolve returns the named features together with the features they
+// require, in registration order. It returns a *ConfigError for unknown
+// features, features that do not support projectType and conflicts.
+func (r *FeatureRegistry) Resolve(projectType string, names []string) ([]Feature, error) {
+	selected := make(map[string]bool)
+	queue := append([]string(nil), names...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if selected[name] {
+			continue
+		}
+
+		f, ok := r.Lookup(name)
+		if !ok {
+			return nil, r.unknown(name)
+		}
+		info := f.Info()
+		if len(info.Types) > 0 && !slices.Contains(info.Types, projectType) {
+			return nil, &ConfigError{
+				Field:  "feature",
+				Value:  name,
+				Reason: fmt.Sprintf("not available for %s projects (supported: %s)", projectType, strings.Join(info.Types, ", ")),
+			}
+		}
+
+		selected[name] = true
+		queue = append(queue, info.Requires...)
+	}
+
+	var features []Feature
+	for _, name := range r.Names() {
+		if !selected[name] {
+			continue
+		}
+		f, _ := r.Lookup(name)
+		for _, other := range f.Info().Conflicts {
+			if selected[other] {
+				return nil, &ConfigError{Field: "feature", Value: name, Reason: fmt.Sprintf("conflicts with %q", other)}
+			}
+		}
+		features = append(features, f)
+	}
+	return features, nil
+}
+
+func (r *FeatureRegistry) unknown(name string) error {
+	available := strings.Join(r.Names(), ", ")
+	reason := "must be one of " + available
+	if s := closest(name, r.Names()); s != "" {
+		reason = fmt.Sprintf("did you mean %q? Available: %s", s, available)
+	}
+	return &ConfigError{Field: "feature", Value: name, Reason: reason}
+}
+
+// addFeatures merges the contributions of features into plan
+func (p *Plan) addFeatures(features []Feature) error {
+	owners := make(map[string]string)
+	for _, f := range p.Files {
+		owners[f.Path] = p.Config.ProjectType + " project type"
+	}
+
+	p.Config.Features = nil
+	for _, f := range features {
+		name := f.Info().Name
+		c := f.Contribute(p.Config)
+
+		for _, file := range c.Files {
+			if owner, ok := owners[file.Path]; ok {
+				return &ConfigError{Field: "feature", Value: name, Reason: fmt.Sprintf("%s is already generated by the %s", file.Path, owner)}
+			}
+			owners[file.Path] = name + " feature"
+		}
+
+		p.Config.Features = append(p.Config.Features, name)
+		p.Directories = append(p.Directories, c.Directories...)
+		p.Files = append(p.Files, c.Files...)
+		for _, dep := range c.Dependencies {
+			if !slices.Contains(p.Dependencies, dep) {
+				p.Dependencies = append(p.Dependencies, dep)
+			}
+		}
+		p.ConfigFields = append(p.ConfigFields, c.ConfigFields...)
+		p.Snippets = append(p.Snippets, c.Snippets...)
+		p.NextSteps = append(p.NextSteps, c.NextSteps...)
+		p.ReadmeSections = append(p.ReadmeSections, c.ReadmeSections...)
+	}
+	return nil
+}
+
+// HasFeature reports whether the project is generated with the feature
+func (d TemplateData) HasFeature(name string) bool {
+	return slices.Contains(d.Features, name)
+}
+
+// SnippetImports returns the sorted imports of the snippets at point
+func (d TemplateData) SnippetImports(point string) []string {
+	var imports []string
+	for _, s := range d.Snippets {
+		if s.Point != point {
+			continue
+		}
+		for _, imp := range s.Imports {
+			if !slices.Contains(imports, imp) {
+				imports = append(imports, imp)
+			}
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// SnippetCode returns the code of the snippets at point, in feature order
+func (d TemplateData) SnippetCode(point string) []string {
+	var code []string
+	for _, s := range d.Snippets {
+		if s.Point == point {
+			code = append(code, s.Code)
+		}
+	}
+	return code
+}
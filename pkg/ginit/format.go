@@ -0,0 +1,91 @@
+package ginit
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// formatGo groups the imports of a Go source file into standard library,
+// third-party and module-local blocks, sorts each block and gofmts the
+// result. Source that does not parse is returned unchanged, so that the
+// type check can report the syntax error.
+func formatGo(module string, src []byte) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return src
+	}
+
+	// Блоки заменяем с конца, чтобы не сдвигать смещения предыдущих
+	out := src
+	for i := len(file.Decls) - 1; i >= 0; i-- {
+		decl, ok := file.Decls[i].(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT || !decl.Lparen.IsValid() {
+			continue
+		}
+		start := fset.Position(decl.Lparen).Offset + 1
+		end := fset.Position(decl.Rparen).Offset
+		block := groupImports(module, fset, src, decl.Specs)
+		out = append(out[:start:start], append([]byte(block), out[end:]...)...)
+	}
+
+	formatted, err := format.Source(out)
+	if err != nil {
+		return src
+	}
+	return formatted
+}
+
+// groupImports renders specs as the body of an import block: standard
+// library, third-party and module-local imports, each sorted by path and
+// separated by a blank line. Comments attached to a spec move with it.
+func groupImports(module string, fset *token.FileSet, src []byte, specs []ast.Spec) string {
+	type spec struct {
+		path string
+		text string
+	}
+	var groups [3][]spec
+	for _, s := range specs {
+		imp := s.(*ast.ImportSpec)
+		from, to := imp.Pos(), imp.End()
+		if imp.Doc != nil {
+			from = imp.Doc.Pos()
+		}
+		if imp.Comment != nil {
+			to = imp.Comment.End()
+		}
+		text := string(src[fset.Position(from).Offset:fset.Position(to).Offset])
+
+		path := strings.Trim(imp.Path.Value, "`\"")
+		group := 1
+		// Модуль без точки в имени (myapp) иначе сошел бы за stdlib
+		switch {
+		case path == module || strings.HasPrefix(path, module+"/"):
+			group = 2
+		case isStdlib(path):
+			group = 0
+		}
+		groups[group] = append(groups[group], spec{path, text})
+	}
+
+	var b bytes.Buffer
+	b.WriteString("\n")
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteString("\n")
+		}
+		sort.SliceStable(group, func(i, j int) bool { return group[i].path < group[j].path })
+		for _, s := range group {
+			b.WriteString("\t" + s.text + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,168 @@
+// Package ginit generates structured Go projects. It is the engine behind
+// the ginit command and can be embedded into other tools.
+//
+// Generation happens in three phases: Plan resolves a Config into the list
+// of directories, files and dependencies of the project, Render executes the
+// templates of a Plan in memory, and Apply writes the result to disk and runs
+// go and git. Generate runs all three.
+//
+//	g := ginit.New(ginit.WithLogger(logger))
+//	result, err := g.Generate(ctx, ginit.Config{
+//		ProjectName: "myapp",
+//		ModuleName:  "github.com/user/myapp",
+//		ProjectType: "web",
+//	})
+package ginit
+
+import (
+	"context"
+	"log/slog"
+)
+
+// MinGoVersion is the oldest Go release the built-in templates support.
+// They rely on log/slog, added in Go 1.21.
+const MinGoVersion = "1.21"
+
+// Config describes the project to generate
+type Config struct {
+	ProjectName string
+	// ModuleName defaults to ProjectName
+	ModuleName string
+	// Directory defaults to ProjectName
+	Directory string
+	// ProjectType defaults to "cli"
+	ProjectType string
+	// Features are added on top of the project type, together with the
+	// features they require
+	Features []string
+	// Variables are read by templates as .Vars.<name>; the project type
+	// declares which it accepts, see VariableType
+	Variables map[string]string
+	InitVCS   bool
+}
+
+// withDefaults fills the optional fields the same way the CLI and TUI do
+func (c Config) withDefaults() Config {
+	if c.ModuleName == "" {
+		c.ModuleName = c.ProjectName
+	}
+	if c.Directory == "" {
+		c.Directory = c.ProjectName
+	}
+	if c.ProjectType == "" {
+		c.ProjectType = "cli"
+	}
+	return c
+}
+
+func (c Config) validate() error {
+	if c.ProjectName == "" {
+		return &ConfigError{Field: "project name", Reason: "must not be empty"}
+	}
+	return nil
+}
+
+// Generator generates projects from the templates of its registry.
+// A Generator is safe for concurrent use as long as its registry is not
+// modified while generating.
+type Generator struct {
+	registry *Registry
+	types    *TypeRegistry
+	features *FeatureRegistry
+	policy   *Policy
+	env      []string
+	reporter Reporter
+	log      *slog.Logger
+}
+
+// Option configures a Generator
+type Option func(*Generator)
+
+// WithRegistry sets the template registry, DefaultRegistry() by default
+func WithRegistry(r *Registry) Option {
+	return func(g *Generator) {
+		g.registry = r
+	}
+}
+
+// WithTypes sets the accepted project types, DefaultTypes() by default
+func WithTypes(r *TypeRegistry) Option {
+	return func(g *Generator) {
+		g.types = r
+	}
+}
+
+// WithFeatures sets the available features, DefaultFeatures() by default
+func WithFeatures(r *FeatureRegistry) Option {
+	return func(g *Generator) {
+		g.features = r
+	}
+}
+
+// WithPolicy makes Plan refuse projects that do not comply with p
+func WithPolicy(p *Policy) Option {
+	return func(g *Generator) {
+		g.policy = p
+	}
+}
+
+// WithEnv adds environment variables, in "key=value" form, to the go and
+// git commands the generator runs, e.g. to use another GOPROXY
+func WithEnv(env ...string) Option {
+	return func(g *Generator) {
+		g.env = append(g.env, env...)
+	}
+}
+
+// WithReporter sets the receiver of progress events
+func WithReporter(r Reporter) Option {
+	return func(g *Generator) {
+		g.reporter = r
+	}
+}
+
+// WithLogger sets the logger for diagnostics and go/git output. Without it
+// the generator is silent: it never writes to stdout or stderr on its own.
+func WithLogger(l *slog.Logger) Option {
+	return func(g *Generator) {
+		g.log = l
+	}
+}
+
+func New(opts ...Option) *Generator {
+	g := &Generator{}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if g.registry == nil {
+		g.registry = DefaultRegistry()
+	}
+	if g.types == nil {
+		g.types = DefaultTypes()
+	}
+	if g.features == nil {
+		g.features = DefaultFeatures()
+	}
+	if g.log == nil {
+		g.log = slog.New(slog.DiscardHandler)
+	}
+	return g
+}
+
+// Generate plans, renders and applies config. The returned Result is nil
+// only if planning or rendering failed; after that it describes the steps
+// that did run, even on error.
+func (g *Generator) Generate(ctx context.Context, config Config) (*Result, error) {
+	plan, err := g.Plan(config)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := g.Render(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Apply(ctx, rendered)
+}
@@ -0,0 +1,271 @@
+package ginit
+
+// Шаблоны файлов типа grpc
+
+const grpcMainTemplate = `package main
+
+import (
+{{template "bootstrap.imports" .}}
+	"os/signal"
+	"syscall"
+	"time"
+
+	"{{.Module}}/internal/server"
+)
+
+func main() {
+{{template "bootstrap" .}}
+	log.InfoContext(ctx, "Starting {{.ProjectName}} gRPC server...")
+{{- template "main.setup" .}}
+
+	// Создание и запуск сервера
+	srv := server.New(cfg, log)
+
+	// Graceful shutdown
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		if err := srv.Run(ctx); err != nil {
+			log.ErrorContext(ctx, "Server failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	<-stop
+	log.InfoContext(ctx, "Shutting down server...")
+
+	// Незавершенные вызовы прерываются по истечении таймаута
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.ErrorContext(ctx, "Graceful shutdown failed", "error", err)
+	}
+
+	log.InfoContext(ctx, "Server stopped")
+}
+`
+
+const grpcConfigTemplate = `package config
+
+import (
+	"sync"
+)
+
+type Config struct {
+	GRPCAddr string
+	LogLevel string
+{{- template "config.fields" .}}
+}
+
+func defaults() *Config {
+	return &Config{
+		GRPCAddr: ":50051",
+		LogLevel: "info",
+{{- template "config.defaults" .}}
+	}
+}
+
+{{template "config.load" .}}
+`
+
+const grpcServerTemplate = `package server
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"{{.Module}}/internal/config"
+)
+
+type Server struct {
+	config *config.Config
+	log    *slog.Logger
+	grpc   *grpc.Server
+	health *health.Server
+}
+
+func New(cfg *config.Config, log *slog.Logger) *Server {
+	s := &Server{
+		config: cfg,
+		log:    log,
+		grpc:   grpc.NewServer(),
+		health: health.NewServer(),
+	}
+
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	reflection.Register(s.grpc)
+
+	// Сервисы из api/proto регистрируются здесь после go generate ./...:
+	// {{snake .ProjectName}}v1.Register{{pascal .ProjectName}}ServiceServer(s.grpc, ...)
+
+	return s
+}
+
+func (s *Server) Run(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.config.GRPCAddr)
+	if err != nil {
+		return err
+	}
+
+	s.log.InfoContext(ctx, "Starting gRPC server", "addr", lis.Addr().String())
+	return s.Serve(lis)
+}
+
+// Serve accepts connections on lis until Shutdown is called
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// Shutdown reports the server as not serving to health checks, stops
+// accepting connections and waits for running calls. When ctx is done
+// first, the remaining calls are cancelled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return ctx.Err()
+	}
+}
+`
+
+const grpcServerTestTemplate = `package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"{{.Module}}/internal/config"
+)
+
+// start serves a new Server on an in-memory listener and returns a client
+// connection to it
+func start(t *testing.T) (*Server, *grpc.ClientConn, <-chan error) {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := New(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return srv, conn, served
+}
+
+func TestHealth(t *testing.T) {
+	_, conn, _ := start(t)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status = %v, want SERVING", resp.GetStatus())
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	srv, conn, served := start(t)
+
+	health := healthpb.NewHealthClient(conn)
+	if _, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve returned %v after Shutdown", err)
+	}
+	if _, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{}); err == nil {
+		t.Error("Check succeeded after Shutdown")
+	}
+}
+`
+
+const grpcProtoTemplate = `syntax = "proto3";
+
+package {{snake .ProjectName}}.v1;
+
+option go_package = "{{.Module}}/api/gen/{{snake .ProjectName}}/v1;{{snake .ProjectName}}v1";
+
+// {{pascal .ProjectName}}Service is the API of {{.ProjectName}}
+service {{pascal .ProjectName}}Service {
+  // Ping returns the message it receives
+  rpc Ping(PingRequest) returns (PingResponse);
+}
+
+message PingRequest {
+  string message = 1;
+}
+
+message PingResponse {
+  string message = 1;
+}
+`
+
+const grpcBufTemplate = `version: v2
+modules:
+  - path: proto
+lint:
+  use:
+    - STANDARD
+breaking:
+  use:
+    - FILE
+`
+
+const grpcBufGenTemplate = `version: v2
+plugins:
+  - remote: buf.build/protocolbuffers/go
+    out: gen
+    opt: paths=source_relative
+  - remote: buf.build/grpc/go
+    out: gen
+    opt: paths=source_relative
+`
+
+const grpcGenerateTemplate = `// Package api holds the protobuf definitions of the service and the Go
+// code generated from them.
+package api
+
+//go:generate buf generate
+`
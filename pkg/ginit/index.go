@@ -0,0 +1,540 @@
+package ginit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// IndexFile records, in the template cache, where the installed template
+// packs came from
+const IndexFile = "index.json"
+
+// Kinds of InstalledTemplate
+const (
+	// KindBuiltin is a project type compiled into ginit
+	KindBuiltin = "built-in"
+	// KindUser is a template pack placed in the user template directory
+	KindUser = "user"
+	// KindCache is a template pack installed into the cache with Install
+	KindCache = "cache"
+)
+
+// InstalledTemplate is an entry of the TemplateIndex
+type InstalledTemplate struct {
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	Kind        string `json:"kind"`
+	// Origin is the git URL or directory a cached pack was installed from
+	Origin string `json:"origin,omitempty"`
+	// Ref is the branch or tag given at install, Commit the installed
+	// revision of a git origin
+	Ref    string `json:"ref,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	// Dir is the pack directory, empty for built-in types
+	Dir       string             `json:"dir,omitempty"`
+	GoVersion string             `json:"go_version,omitempty"`
+	Extends   string             `json:"extends,omitempty"`
+	Variables []TemplateVariable `json:"variables,omitempty"`
+	// Checksum covers the files of a cached pack as installed, SignedBy
+	// names the trusted key its signature was verified with
+	Checksum string `json:"checksum,omitempty"`
+	SignedBy string `json:"signed_by,omitempty"`
+	// Error tells why a pack could not be loaded; such packs are listed
+	// but not registered
+	Error string `json:"-"`
+}
+
+// TemplateIndex lists the templates projects can be generated from: the
+// built-in project types, the packs in the user template directory and
+// the packs installed into the template cache. A pack in the user
+// directory takes precedence over a cached one with the same name, both
+// over a built-in type.
+type TemplateIndex struct {
+	userDir   string
+	cacheDir  string
+	keys      []TrustedKey
+	templates []InstalledTemplate
+}
+
+// TemplateDirs returns the user template directory, templates/ next to the
+// settings file, and the template cache, ginit/templates in the user cache
+// directory or $GINIT_CACHE
+func TemplateDirs() (user, cache string, err error) {
+	settings, err := SettingsPath()
+	if err != nil {
+		return "", "", err
+	}
+	cache = os.Getenv("GINIT_CACHE")
+	if cache == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", "", err
+		}
+		cache = filepath.Join(dir, "ginit", "templates")
+	}
+	return filepath.Join(filepath.Dir(settings), "templates"), cache, nil
+}
+
+// LoadTemplateIndex reads the packs of userDir and cacheDir. Missing or
+// empty directories have no packs; a pack that fails to load is listed
+// with Error.
+func LoadTemplateIndex(userDir, cacheDir string) (*TemplateIndex, error) {
+	ix := &TemplateIndex{userDir: userDir, cacheDir: cacheDir}
+	for _, t := range DefaultTypes().List() {
+		ix.templates = append(ix.templates, InstalledTemplate{
+			Name:        t.Name,
+			Description: t.Description,
+			Kind:        KindBuiltin,
+			GoVersion:   MinGoVersion,
+		})
+	}
+
+	cached, err := ix.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range cached {
+		t = withManifest(t)
+		if t.Error == "" {
+			if err := verifyInstalled(t); err != nil {
+				t.Error = err.Error()
+			}
+		}
+		ix.templates = append(ix.templates, t)
+	}
+
+	entries, err := os.ReadDir(userDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		dir := filepath.Join(userDir, e.Name())
+		if _, err := os.Stat(filepath.Join(dir, PackManifestFile)); err != nil {
+			continue
+		}
+		ix.templates = append(ix.templates, withManifest(InstalledTemplate{Name: e.Name(), Kind: KindUser, Dir: dir}))
+	}
+	return ix, nil
+}
+
+// withManifest fills t from the manifest of the pack in t.Dir
+func withManifest(t InstalledTemplate) InstalledTemplate {
+	p, err := LoadPack(t.Dir)
+	if err != nil {
+		t.Error = err.Error()
+		return t
+	}
+	m := p.Manifest
+	t.Name, t.Version, t.Description = m.Name, m.Version, m.Description
+	t.GoVersion, t.Extends, t.Variables = m.GoVersion, m.Extends, m.Variables
+	return t
+}
+
+// Trust sets the keys template packs must be signed with. Install and
+// Update then refuse packs without a valid signature by one of them, and
+// Register checks the signatures of cached and user packs before adding
+// them. Without trusted keys only the checksums recorded at installation
+// are checked.
+func (ix *TemplateIndex) Trust(keys []TrustedKey) {
+	ix.keys = keys
+}
+
+// VerifyPack checks the pack in dir, named name in errors, like Register
+// checks the packs of the index: with trusted keys it must match its
+// PackSumFile and be signed by one of them. Use it for packs loaded from
+// elsewhere, e.g. with LoadPack.
+func (ix *TemplateIndex) VerifyPack(ctx context.Context, name, dir string) error {
+	if len(ix.keys) == 0 {
+		return nil
+	}
+	_, _, err := verifyPack(ctx, name, dir, ix.keys)
+	return err
+}
+
+// List returns the templates in order of precedence, lowest first
+func (ix *TemplateIndex) List() []InstalledTemplate {
+	return slices.Clone(ix.templates)
+}
+
+// Lookup returns the template used for name
+func (ix *TemplateIndex) Lookup(name string) (InstalledTemplate, bool) {
+	for i := len(ix.templates) - 1; i >= 0; i-- {
+		if ix.templates[i].Name == name && ix.templates[i].Error == "" {
+			return ix.templates[i], true
+		}
+	}
+	return InstalledTemplate{}, false
+}
+
+// Register adds the packs of the index to templates and types in order of
+// precedence, each after the pack it extends. Packs that cannot be loaded
+// or registered are skipped and listed with Error; the returned error
+// joins their errors.
+func (ix *TemplateIndex) Register(templates *Registry, types *TypeRegistry) error {
+	var errs []error
+	fail := func(i int, err error) {
+		ix.templates[i].Error = err.Error()
+		errs = append(errs, err)
+	}
+
+	packs := make(map[*Pack]int)
+	var pending []*Pack
+	for i, t := range ix.templates {
+		switch {
+		case t.Kind == KindBuiltin:
+			continue
+		case t.Error != "":
+			errs = append(errs, errors.New(t.Error))
+			continue
+		}
+		if err := ix.VerifyPack(context.Background(), t.Name, t.Dir); err != nil {
+			fail(i, err)
+			continue
+		}
+		p, err := LoadPack(t.Dir)
+		if err != nil {
+			fail(i, err)
+			continue
+		}
+		packs[p] = i
+		pending = append(pending, p)
+	}
+
+	// Пакет регистрируется после базового, если тот тоже установлен
+	for len(pending) > 0 {
+		var rest []*Pack
+		for _, p := range pending {
+			if slices.ContainsFunc(pending, func(other *Pack) bool {
+				return other != p && other.Manifest.Name == p.Manifest.Extends
+			}) {
+				rest = append(rest, p)
+				continue
+			}
+			if err := p.Register(templates, types); err != nil {
+				fail(packs[p], err)
+			}
+		}
+		if len(rest) == len(pending) {
+			for _, p := range rest {
+				fail(packs[p], fmt.Errorf("template pack %s: extends %s, which extends it in turn", p.Manifest.Name, p.Manifest.Extends))
+			}
+			break
+		}
+		pending = rest
+	}
+	return errors.Join(errs...)
+}
+
+// Install fetches the template pack at source, a git URL or a local
+// directory, into the cache. ref selects a branch or tag of a git source.
+func (ix *TemplateIndex) Install(ctx context.Context, source, ref string) (InstalledTemplate, error) {
+	t, err := ix.fetch(ctx, source, ref)
+	if err != nil {
+		return InstalledTemplate{}, err
+	}
+
+	cached, err := ix.readIndex()
+	if err != nil {
+		os.RemoveAll(t.Dir)
+		return InstalledTemplate{}, err
+	}
+	if slices.ContainsFunc(cached, func(c InstalledTemplate) bool { return c.Name == t.Name }) {
+		os.RemoveAll(t.Dir)
+		return InstalledTemplate{}, fmt.Errorf("template %s is already installed, update it with 'ginit templates update %s'", t.Name, t.Name)
+	}
+
+	dir := filepath.Join(ix.cacheDir, t.Name)
+	if err := os.RemoveAll(dir); err != nil {
+		return InstalledTemplate{}, err
+	}
+	if err := os.Rename(t.Dir, dir); err != nil {
+		os.RemoveAll(t.Dir)
+		return InstalledTemplate{}, err
+	}
+	t.Dir = dir
+
+	if err := ix.writeIndex(append(cached, t)); err != nil {
+		return InstalledTemplate{}, err
+	}
+	// Установленный пакет уступает пакетам пользовательского каталога
+	i := slices.IndexFunc(ix.templates, func(t InstalledTemplate) bool { return t.Kind == KindUser })
+	if i < 0 {
+		i = len(ix.templates)
+	}
+	ix.templates = slices.Insert(ix.templates, i, t)
+	return t, nil
+}
+
+// Update fetches a cached pack again from its origin and returns the
+// previous and the new entry. The previous entry has Error set if it could
+// not be used, e.g. because it was modified in the cache.
+func (ix *TemplateIndex) Update(ctx context.Context, name string) (old, updated InstalledTemplate, err error) {
+	cached, err := ix.readIndex()
+	if err != nil {
+		return old, updated, err
+	}
+	i := slices.IndexFunc(cached, func(c InstalledTemplate) bool { return c.Name == name })
+	if i < 0 {
+		return old, updated, ix.notCached(name)
+	}
+	old = withManifest(cached[i])
+	if old.Error == "" {
+		if err := verifyInstalled(old); err != nil {
+			old.Error = err.Error()
+		}
+	}
+
+	updated, err = ix.fetch(ctx, old.Origin, old.Ref)
+	if err != nil {
+		return old, updated, err
+	}
+	if updated.Name != name {
+		os.RemoveAll(updated.Dir)
+		return old, updated, fmt.Errorf("%s now holds template %s instead of %s", old.Origin, updated.Name, name)
+	}
+
+	// Старую версию удаляем только после того, как новая на месте
+	backup := old.Dir + ".old"
+	if err := os.Rename(old.Dir, backup); err != nil {
+		os.RemoveAll(updated.Dir)
+		return old, updated, err
+	}
+	if err := os.Rename(updated.Dir, old.Dir); err != nil {
+		os.Rename(backup, old.Dir)
+		os.RemoveAll(updated.Dir)
+		return old, updated, err
+	}
+	os.RemoveAll(backup)
+	updated.Dir = old.Dir
+
+	cached[i] = updated
+	if err := ix.writeIndex(cached); err != nil {
+		return old, updated, err
+	}
+	ix.replace(updated)
+	return old, updated, nil
+}
+
+// Remove deletes a pack from the cache
+func (ix *TemplateIndex) Remove(name string) error {
+	cached, err := ix.readIndex()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(cached, func(c InstalledTemplate) bool { return c.Name == name })
+	if i < 0 {
+		return ix.notCached(name)
+	}
+	if err := os.RemoveAll(cached[i].Dir); err != nil {
+		return err
+	}
+	if err := ix.writeIndex(slices.Delete(cached, i, i+1)); err != nil {
+		return err
+	}
+	ix.templates = slices.DeleteFunc(ix.templates, func(t InstalledTemplate) bool {
+		return t.Kind == KindCache && t.Name == name
+	})
+	return nil
+}
+
+// Cached returns the names of the packs in the cache
+func (ix *TemplateIndex) Cached() []string {
+	var names []string
+	for _, t := range ix.templates {
+		if t.Kind == KindCache {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+func (ix *TemplateIndex) replace(t InstalledTemplate) {
+	for i := range ix.templates {
+		if ix.templates[i].Kind == KindCache && ix.templates[i].Name == t.Name {
+			ix.templates[i] = t
+		}
+	}
+}
+
+// notCached explains why name cannot be updated or removed
+func (ix *TemplateIndex) notCached(name string) error {
+	for _, t := range ix.templates {
+		if t.Name != name {
+			continue
+		}
+		switch t.Kind {
+		case KindBuiltin:
+			return fmt.Errorf("template %s is built into ginit", name)
+		case KindUser:
+			return fmt.Errorf("template %s is in the user template directory, manage it in %s", name, t.Dir)
+		}
+	}
+	var names []string
+	for _, t := range ix.templates {
+		names = append(names, t.Name)
+	}
+	if s := closest(name, names); s != "" {
+		return fmt.Errorf("template %s is not installed, did you mean %s?", name, s)
+	}
+	return fmt.Errorf("template %s is not installed", name)
+}
+
+// fetch copies or clones source into a new directory of the cache and
+// loads the pack from it
+func (ix *TemplateIndex) fetch(ctx context.Context, source, ref string) (InstalledTemplate, error) {
+	if ix.cacheDir == "" {
+		return InstalledTemplate{}, errors.New("no template cache directory, set GINIT_CACHE")
+	}
+	if err := os.MkdirAll(ix.cacheDir, 0755); err != nil {
+		return InstalledTemplate{}, err
+	}
+	dir, err := os.MkdirTemp(ix.cacheDir, ".fetch-")
+	if err != nil {
+		return InstalledTemplate{}, err
+	}
+
+	// MkdirTemp создает каталог только для владельца
+	if err := os.Chmod(dir, 0755); err != nil {
+		os.RemoveAll(dir)
+		return InstalledTemplate{}, err
+	}
+
+	t := InstalledTemplate{Kind: KindCache, Origin: source, Ref: ref, Dir: dir}
+	switch {
+	case isDir(filepath.Join(source, ".git")):
+		t.Origin, _ = filepath.Abs(source)
+		t.Commit, err = gitClone(ctx, t.Origin, ref, dir)
+	case isDir(source):
+		if ref != "" {
+			os.RemoveAll(dir)
+			return t, fmt.Errorf("%s is not a git repository, it has no ref %s", source, ref)
+		}
+		t.Origin, _ = filepath.Abs(source)
+		err = copyDir(source, dir)
+	default:
+		t.Commit, err = gitClone(ctx, source, ref, dir)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return t, err
+	}
+
+	t = withManifest(t)
+	if t.Error != "" {
+		os.RemoveAll(dir)
+		return t, errors.New(t.Error)
+	}
+	if strings.ContainsAny(t.Name, `/\`) || t.Name == "." || t.Name == ".." || strings.HasPrefix(t.Name, ".") {
+		os.RemoveAll(dir)
+		return t, fmt.Errorf("template pack name %q cannot be installed, it must be usable as a directory name", t.Name)
+	}
+
+	if t.Checksum, t.SignedBy, err = verifyPack(ctx, t.Name, dir, ix.keys); err != nil {
+		os.RemoveAll(dir)
+		return t, err
+	}
+	// Файл сумм в кэше позволяет потом показать, какие файлы изменились
+	if _, err := os.Stat(filepath.Join(dir, PackSumFile)); errors.Is(err, fs.ErrNotExist) {
+		if err := WriteChecksums(dir); err != nil {
+			os.RemoveAll(dir)
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+func isDir(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.IsDir()
+}
+
+// gitClone clones ref of url into dir without history and returns the
+// cloned commit. The .git directory is removed, updates clone again.
+func gitClone(ctx context.Context, url, ref, dir string) (string, error) {
+	args := []string{"clone", "--depth", "1", "--quiet"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", url, dir)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", &CommandError{Command: cmd.String(), Output: strings.Split(strings.TrimSpace(string(out)), "\n"), Err: err}
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(out)), os.RemoveAll(filepath.Join(dir, ".git"))
+}
+
+// copyDir copies the regular files below src into dst, leaving out .git.
+// Symlinks are copied as the files they point to, see packLink.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir() && d.Name() == ".git":
+			return filepath.SkipDir
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type()&fs.ModeSymlink != 0:
+			if err := packLink(src, path); err != nil {
+				return err
+			}
+		case !d.Type().IsRegular():
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+}
+
+func (ix *TemplateIndex) readIndex() ([]InstalledTemplate, error) {
+	if ix.cacheDir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(ix.cacheDir, IndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var templates []InstalledTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Join(ix.cacheDir, IndexFile), err)
+	}
+	return templates, nil
+}
+
+func (ix *TemplateIndex) writeIndex(templates []InstalledTemplate) error {
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(ix.cacheDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(ix.cacheDir, IndexFile), append(data, '\n'), 0644)
+}
@@ -0,0 +1,323 @@
+package ginit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PackSumFile lists the SHA-256 checksums of the files of a template pack
+// in sha256sum format. A signature of it is stored next to it, with
+// SSHSignatureSuffix or MinisignSuffix appended.
+const PackSumFile = "ginit-template.sum"
+
+// Signature files of PackSumFile and the namespace of SSH signatures
+const (
+	SSHSignatureSuffix = ".sig"
+	MinisignSuffix     = ".minisig"
+	SSHNamespace       = "ginit-template"
+)
+
+// Types of TrustedKey
+const (
+	KeySSH      = "ssh"
+	KeyMinisign = "minisign"
+)
+
+// TrustedKey is a public key template packs may be signed with
+type TrustedKey struct {
+	// Name identifies the key in messages and in the template index
+	Name string `json:"name"`
+	// Type is KeySSH or KeyMinisign
+	Type string `json:"type"`
+	// Key is the public key, e.g. "ssh-ed25519 AAAA..." or the base64
+	// minisign key "RWQ..."
+	Key string `json:"key"`
+}
+
+// PackChecksums returns the contents of PackSumFile for the pack in dir:
+// the checksums of all files, symlinks resolved, except PackSumFile and its
+// signatures, sorted by path
+func PackChecksums(dir string) ([]byte, error) {
+	sums, err := packSums(dir)
+	if err != nil {
+		return nil, err
+	}
+	return formatSums(sums), nil
+}
+
+// WriteChecksums writes PackSumFile into the pack in dir. Sign it
+// afterwards with ssh-keygen -Y sign -n ginit-template or minisign -S.
+func WriteChecksums(dir string) error {
+	data, err := PackChecksums(dir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, PackSumFile), data, 0644)
+}
+
+// verifyPack checks the pack in dir against its PackSumFile, if present,
+// and, with trusted keys, its signature. It returns the checksum of the
+// pack and the name of the key it is signed with. Without trusted keys
+// signatures are not checked; with them, the pack must be signed by one.
+func verifyPack(ctx context.Context, name, dir string, keys []TrustedKey) (checksum, signedBy string, err error) {
+	sums, err := packSums(dir)
+	if err != nil {
+		return "", "", err
+	}
+	listing := formatSums(sums)
+
+	sumFile := filepath.Join(dir, PackSumFile)
+	recorded, err := os.ReadFile(sumFile)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if len(keys) > 0 {
+			return "", "", notSigned(name)
+		}
+		return packChecksum(listing), "", nil
+	case err != nil:
+		return "", "", err
+	}
+
+	// Файл может быть записан и sha256sum, с путями вида ./files/main.go
+	if diff := diffSums(parseSums(recorded), sums); len(diff) > 0 {
+		return "", "", &IntegrityError{
+			Pack:   name,
+			Reason: "files do not match " + PackSumFile,
+			Files:  diff,
+		}
+	}
+	if len(keys) > 0 {
+		if signedBy, err = verifySignature(ctx, name, sumFile, keys); err != nil {
+			return "", "", err
+		}
+	}
+	return packChecksum(listing), signedBy, nil
+}
+
+// verifyInstalled checks that a cached pack is unchanged since it was
+// installed
+func verifyInstalled(t InstalledTemplate) error {
+	if t.Checksum == "" {
+		return &IntegrityError{Pack: t.Name, Reason: "no checksum was recorded at installation, reinstall it with 'ginit templates update " + t.Name + "'"}
+	}
+	sums, err := packSums(t.Dir)
+	if err != nil {
+		return err
+	}
+	if packChecksum(formatSums(sums)) == t.Checksum {
+		return nil
+	}
+
+	// Файл сумм пишется при установке и показывает, что именно изменилось
+	e := &IntegrityError{Pack: t.Name, Reason: "it was modified after installation"}
+	if recorded, err := os.ReadFile(filepath.Join(t.Dir, PackSumFile)); err == nil {
+		e.Files = diffSums(parseSums(recorded), sums)
+	}
+	return e
+}
+
+// verifySignature checks the signatures of sumFile against the trusted
+// keys of their type and returns the name of the matching key. Each kind
+// of signature present is tried; the pack fails only if none is valid.
+func verifySignature(ctx context.Context, name, sumFile string, keys []TrustedKey) (string, error) {
+	var reasons []string
+	for _, kind := range []struct{ suffix, keyType string }{
+		{SSHSignatureSuffix, KeySSH},
+		{MinisignSuffix, KeyMinisign},
+	} {
+		sig, keyType := sumFile+kind.suffix, kind.keyType
+		if _, err := os.Stat(sig); err != nil {
+			continue
+		}
+
+		tried := 0
+		var toolErr error
+		for _, key := range keys {
+			if key.Type != keyType {
+				continue
+			}
+			tried++
+			var err error
+			if keyType == KeySSH {
+				err = verifySSH(ctx, sumFile, sig, key.Key)
+			} else {
+				err = verifyMinisign(ctx, sumFile, sig, key.Key)
+			}
+			if err == nil {
+				return key.Name, nil
+			}
+			var cmdErr *CommandError
+			if !errors.As(err, &cmdErr) {
+				toolErr = err
+				break
+			}
+		}
+		switch {
+		case tried == 0:
+			reasons = append(reasons, fmt.Sprintf("it is signed with %s, but no %s key is trusted", filepath.Base(sig), keyType))
+		case toolErr != nil:
+			reasons = append(reasons, fmt.Sprintf("%s could not be checked: %v", filepath.Base(sig), toolErr))
+		default:
+			reasons = append(reasons, filepath.Base(sig)+" is not a valid signature by a trusted key")
+		}
+	}
+	if len(reasons) == 0 {
+		return "", notSigned(name)
+	}
+	return "", &IntegrityError{Pack: name, Reason: strings.Join(reasons, "; ")}
+}
+
+func notSigned(name string) error {
+	return &IntegrityError{Pack: name, Reason: fmt.Sprintf("it is not signed, but trusted keys are configured; sign %s as %s%s or %s%s",
+		PackSumFile, PackSumFile, SSHSignatureSuffix, PackSumFile, MinisignSuffix)}
+}
+
+// verifySSH verifies an SSH signature of file with ssh-keygen
+func verifySSH(ctx context.Context, file, sig, key string) error {
+	signers, err := os.CreateTemp("", "ginit-allowed-signers-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(signers.Name())
+	_, err = fmt.Fprintf(signers, "ginit namespaces=%q %s\n", SSHNamespace, key)
+	if closeErr := signers.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "ssh-keygen", "-Y", "verify", "-f", signers.Name(), "-I", "ginit", "-n", SSHNamespace, "-s", sig)
+	cmd.Stdin = bytes.NewReader(data)
+	return runVerify(cmd)
+}
+
+// verifyMinisign verifies a minisign signature of file with minisign
+func verifyMinisign(ctx context.Context, file, sig, key string) error {
+	return runVerify(exec.CommandContext(ctx, "minisign", "-V", "-q", "-m", file, "-x", sig, "-P", key))
+}
+
+// runVerify runs a signature check. A missing tool is an error of its
+// own, a failed check a *CommandError.
+func runVerify(cmd *exec.Cmd) error {
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exitErr):
+		return &CommandError{Command: cmd.String(), Output: strings.Split(strings.TrimSpace(string(out)), "\n"), Err: err}
+	default:
+		return fmt.Errorf("verify signature: %w", err)
+	}
+}
+
+// packSums returns the checksums of the files of the pack in dir by
+// slash-separated path. A symlink is checksummed with the content of the
+// file it points to, which must be inside the pack as for readPackDir.
+func packSums(dir string) (map[string]string, error) {
+	sums := make(map[string]string)
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case d.IsDir() && d.Name() == ".git":
+			return filepath.SkipDir
+		case rel == PackSumFile || rel == PackSumFile+SSHSignatureSuffix || rel == PackSumFile+MinisignSuffix:
+			return nil
+		case d.Type()&fs.ModeSymlink != 0:
+			if err := packLink(dir, file); err != nil {
+				return err
+			}
+		case !d.Type().IsRegular():
+			return nil
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		sums[rel] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	return sums, err
+}
+
+func formatSums(sums map[string]string) []byte {
+	paths := make([]string, 0, len(sums))
+	for p := range sums {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var b bytes.Buffer
+	for _, p := range paths {
+		fmt.Fprintf(&b, "%s  %s\n", sums[p], p)
+	}
+	return b.Bytes()
+}
+
+// parseSums reads sha256sum output, in text or binary ("*path") mode and
+// with or without a leading "./"
+func parseSums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		sum, file, ok := strings.Cut(strings.TrimSuffix(scanner.Text(), "\r"), " ")
+		if !ok || len(file) < 2 || (file[0] != ' ' && file[0] != '*') {
+			continue
+		}
+		sums[strings.TrimPrefix(file[1:], "./")] = strings.ToLower(sum)
+	}
+	return sums
+}
+
+// diffSums describes the files that differ between the recorded and the
+// actual checksums
+func diffSums(recorded, actual map[string]string) []string {
+	var diff []string
+	for p, sum := range actual {
+		switch r, ok := recorded[p]; {
+		case !ok:
+			diff = append(diff, "added "+p)
+		case r != sum:
+			diff = append(diff, "changed "+p)
+		}
+	}
+	for p := range recorded {
+		if _, ok := actual[p]; !ok {
+			diff = append(diff, "removed "+p)
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i][strings.IndexByte(diff[i], ' '):] < diff[j][strings.IndexByte(diff[j], ' '):]
+	})
+	return diff
+}
+
+func packChecksum(listing []byte) string {
+	sum := sha256.Sum256(listing)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
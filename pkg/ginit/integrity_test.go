@@ -0,0 +1,306 @@
+package ginit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes the files, by slash-separated path, below dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// fakeMinisign puts a minisign on PATH that accepts every signature by the
+// key "RWgood" and rejects all others
+func fakeMinisign(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	script := "#!/bin/sh\nfor a; do last=$a; done\n[ \"$last\" = RWgood ] || { echo 'Signature verification failed' >&2; exit 1; }\n"
+	if err := os.WriteFile(filepath.Join(bin, "minisign"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// sshKey generates an SSH key and returns a function signing a file with
+// it, and the public key
+func sshKey(t *testing.T) (sign func(file string), public string) {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v\n%s", err, out)
+	}
+	pub, err := os.ReadFile(key + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(file string) {
+		t.Helper()
+		if out, err := exec.Command("ssh-keygen", "-Y", "sign", "-f", key, "-n", SSHNamespace, file).CombinedOutput(); err != nil {
+			t.Fatalf("ssh-keygen -Y sign: %v\n%s", err, out)
+		}
+	}, strings.TrimSpace(string(pub))
+}
+
+func TestVerifyPack(t *testing.T) {
+	fakeMinisign(t)
+	sign, trusted := sshKey(t)
+	signOther, untrusted := sshKey(t)
+
+	sshKeys := []TrustedKey{{Name: "team", Type: KeySSH, Key: trusted}}
+	minisignKeys := []TrustedKey{{Name: "releases", Type: KeyMinisign, Key: "RWgood"}}
+	bothKeys := append([]TrustedKey{{Name: "other", Type: KeySSH, Key: untrusted}}, minisignKeys...)
+
+	tests := []struct {
+		name string
+		// sums writes PackSumFile before setup changes the pack
+		sums  bool
+		setup func(dir string)
+		keys  []TrustedKey
+		// signedBy is the expected key, err a part of the expected error
+		signedBy string
+		err      string
+		files    []string
+	}{
+		{name: "no sums, no keys"},
+		{name: "no sums, keys", keys: sshKeys, err: "it is not signed"},
+		{name: "sums, no keys", sums: true},
+		{
+			name: "changed file", sums: true,
+			setup: func(dir string) { writeFiles(t, dir, map[string]string{"files/main.go.tmpl": "package evil\n"}) },
+			err:   "files do not match", files: []string{"changed files/main.go.tmpl"},
+		},
+		{
+			name: "added and removed files", sums: true,
+			setup: func(dir string) {
+				writeFiles(t, dir, map[string]string{"files/extra.tmpl": "x"})
+				os.Remove(filepath.Join(dir, "files", "README.md.tmpl"))
+			},
+			err: "files do not match", files: []string{"removed files/README.md.tmpl", "added files/extra.tmpl"},
+		},
+		{
+			name: "tampered sum file", sums: true,
+			setup: func(dir string) {
+				sum := filepath.Join(dir, PackSumFile)
+				data, _ := os.ReadFile(sum)
+				os.WriteFile(sum, []byte(strings.Replace(string(data), "files/main.go.tmpl", "files/other.go.tmpl", 1)), 0644)
+			},
+			err: "files do not match", files: []string{"added files/main.go.tmpl", "removed files/other.go.tmpl"},
+		},
+		{
+			name: "sha256sum file", sums: true,
+			setup: func(dir string) {
+				// Как после find . -type f | xargs sha256sum: пути с ./, свой порядок
+				sum := filepath.Join(dir, PackSumFile)
+				data, _ := os.ReadFile(sum)
+				lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+				for i, line := range lines {
+					lines[i] = strings.Replace(line, "  ", "  ./", 1)
+				}
+				lines[0], lines[len(lines)-1] = lines[len(lines)-1], lines[0]
+				lines[1] = strings.Replace(lines[1], "  ./", " *./", 1)
+				os.WriteFile(sum, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+			},
+		},
+		{
+			name: "sha256sum file, then changed", sums: true,
+			setup: func(dir string) {
+				sum := filepath.Join(dir, PackSumFile)
+				data, _ := os.ReadFile(sum)
+				os.WriteFile(sum, []byte(strings.ReplaceAll(string(data), "  ", "  ./")), 0644)
+				writeFiles(t, dir, map[string]string{"files/main.go.tmpl": "package evil\n"})
+			},
+			err: "files do not match", files: []string{"changed files/main.go.tmpl"},
+		},
+		{name: "sums without signature", sums: true, keys: sshKeys, err: "it is not signed"},
+		{
+			name: "signed by trusted key", sums: true, keys: sshKeys, signedBy: "team",
+			setup: func(dir string) { sign(filepath.Join(dir, PackSumFile)) },
+		},
+		{
+			name: "signed by untrusted key", sums: true, keys: sshKeys,
+			setup: func(dir string) { signOther(filepath.Join(dir, PackSumFile)) },
+			err:   PackSumFile + SSHSignatureSuffix + " is not a valid signature by a trusted key",
+		},
+		{
+			name: "signed, then changed", sums: true, keys: sshKeys,
+			setup: func(dir string) {
+				sign(filepath.Join(dir, PackSumFile))
+				writeFiles(t, dir, map[string]string{"files/main.go.tmpl": "package evil\n"})
+			},
+			err: "files do not match", files: []string{"changed files/main.go.tmpl"},
+		},
+		{
+			name: "ssh signature, no ssh key trusted", sums: true, keys: minisignKeys,
+			setup: func(dir string) { sign(filepath.Join(dir, PackSumFile)) },
+			err:   "no ssh key is trusted",
+		},
+		{
+			name: "ssh signature untrusted, minisign valid", sums: true, keys: minisignKeys, signedBy: "releases",
+			setup: func(dir string) {
+				sign(filepath.Join(dir, PackSumFile))
+				writeFiles(t, dir, map[string]string{PackSumFile + MinisignSuffix: "signature"})
+			},
+		},
+		{
+			name: "ssh signature invalid, minisign valid", sums: true, keys: bothKeys, signedBy: "releases",
+			setup: func(dir string) {
+				sign(filepath.Join(dir, PackSumFile))
+				writeFiles(t, dir, map[string]string{PackSumFile + MinisignSuffix: "signature"})
+			},
+		},
+		{
+			name: "both signatures invalid", sums: true,
+			keys: []TrustedKey{{Name: "team", Type: KeySSH, Key: trusted}, {Name: "other", Type: KeyMinisign, Key: "RWbad"}},
+			setup: func(dir string) {
+				signOther(filepath.Join(dir, PackSumFile))
+				writeFiles(t, dir, map[string]string{PackSumFile + MinisignSuffix: "signature"})
+			},
+			err: PackSumFile + SSHSignatureSuffix + " is not a valid signature by a trusted key; " + PackSumFile + MinisignSuffix + " is not a valid signature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				PackManifestFile:       `{"name": "svc"}`,
+				"files/main.go.tmpl":   "package main\n",
+				"files/README.md.tmpl": "# {{.ProjectName}}\n",
+				"partials/banner.tmpl": "banner",
+				".git/HEAD":            "ref: refs/heads/main\n",
+			})
+			if tt.sums {
+				if err := WriteChecksums(dir); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.setup != nil {
+				tt.setup(dir)
+			}
+
+			checksum, signedBy, err := verifyPack(context.Background(), "svc", dir, tt.keys)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("verifyPack: %v", err)
+				}
+				if !strings.HasPrefix(checksum, "sha256:") || signedBy != tt.signedBy {
+					t.Errorf("verifyPack = %q, %q; want a checksum signed by %q", checksum, signedBy, tt.signedBy)
+				}
+				return
+			}
+
+			var ie *IntegrityError
+			if !errors.As(err, &ie) {
+				t.Fatalf("verifyPack = %v, want an IntegrityError", err)
+			}
+			if !strings.Contains(ie.Reason, tt.err) {
+				t.Errorf("reason %q does not contain %q", ie.Reason, tt.err)
+			}
+			if strings.Join(ie.Files, ",") != strings.Join(tt.files, ",") {
+				t.Errorf("files = %q, want %q", ie.Files, tt.files)
+			}
+		})
+	}
+}
+
+func TestPackSums(t *testing.T) {
+	sha := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	tests := []struct {
+		name string
+		// links maps symlink paths to their targets
+		links map[string]string
+		want  map[string]string
+		err   string
+	}{
+		{
+			name: "regular files",
+			want: map[string]string{"files/main.go.tmpl": sha("package main\n"), "shared/header.tmpl": sha("header")},
+		},
+		{
+			name:  "symlink inside the pack",
+			links: map[string]string{"files/header.tmpl": "../shared/header.tmpl"},
+			want: map[string]string{
+				"files/main.go.tmpl": sha("package main\n"),
+				"files/header.tmpl":  sha("header"),
+				"shared/header.tmpl": sha("header"),
+			},
+		},
+		{
+			name:  "symlink escaping the pack",
+			links: map[string]string{"files/secret.tmpl": "../../outside"},
+			err:   "files/secret.tmpl is a symlink to ../../outside, outside the pack",
+		},
+		{
+			name:  "absolute symlink",
+			links: map[string]string{"files/passwd.tmpl": "/etc/passwd"},
+			err:   "outside the pack",
+		},
+		{
+			name:  "dangling symlink",
+			links: map[string]string{"files/missing.tmpl": "missing.tmpl"},
+			err:   "which does not exist",
+		},
+		{
+			name:  "symlink to a directory",
+			links: map[string]string{"files/shared": "../shared"},
+			err:   "symlink to a directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			writeFiles(t, parent, map[string]string{"outside": "secret"})
+			dir := filepath.Join(parent, "pack")
+			writeFiles(t, dir, map[string]string{
+				"files/main.go.tmpl":             "package main\n",
+				"shared/header.tmpl":             "header",
+				PackSumFile:                      "ignored",
+				PackSumFile + SSHSignatureSuffix: "ignored",
+				PackSumFile + MinisignSuffix:     "ignored",
+				".git/config":                    "ignored",
+			})
+			for link, target := range tt.links {
+				if err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(link))); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			sums, err := packSums(dir)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("packSums = %v, want an error containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("packSums: %v", err)
+			}
+			if string(formatSums(sums)) != string(formatSums(tt.want)) {
+				t.Errorf("packSums =\n%s\nwant\n%s", formatSums(sums), formatSums(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,617 @@
+package ginit
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// LintIssue is a problem LintPack found in a template pack
+type LintIssue struct {
+	// File is relative to the pack directory, e.g. files/main.go.tmpl
+	File string
+	// Line is 0 when the issue is not about a particular line
+	Line int
+	Msg  string
+	// Warning marks issues that do not break generation
+	Warning bool
+}
+
+func (i LintIssue) String() string {
+	pos := i.File
+	if i.Line > 0 {
+		pos += ":" + strconv.Itoa(i.Line)
+	}
+	if i.Warning {
+		return pos + ": warning: " + i.Msg
+	}
+	return pos + ": " + i.Msg
+}
+
+// LintPack checks the template pack in dir without generating a project:
+// every template and file path must parse with FuncMap, refer only to
+// existing fields and declared variables and render for sample data to a
+// unique path inside the project; conditions must be well-formed and the
+// rendered Go files must type-check. Unused variables are reported as
+// warnings. The error is non-nil only if the pack cannot be loaded.
+func LintPack(dir string) ([]LintIssue, error) {
+	p, err := LoadPack(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{pack: p, used: make(map[string]bool), partials: make(map[string]bool)}
+	for name := range DefaultRegistry().Partials() {
+		l.partials[name] = true
+	}
+	for _, f := range p.Partials {
+		l.partials[f.Path] = true
+	}
+
+	l.lintManifest()
+	for _, f := range p.Partials {
+		l.lintTemplate(PackPartialsDir+"/"+f.Source, f.Content, false)
+	}
+	for _, f := range p.Files {
+		l.lintFile(f)
+	}
+	l.lintPaths()
+	l.lintGo()
+
+	for _, v := range p.Manifest.Variables {
+		// Без регистрации патчи не проверены, и переменная может быть в них
+		if !l.used[v.Name] && l.registered {
+			l.warn(PackManifestFile, 0, fmt.Sprintf("variable %q is not used by any template", v.Name))
+		}
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return l.issues, nil
+}
+
+// linter collects the issues of a pack
+type linter struct {
+	pack   *Pack
+	used   map[string]bool
+	issues []LintIssue
+	// registered is set once the pack was registered with its base type
+	registered bool
+	// partials are the names of the built-in partials and those of the pack
+	partials map[string]bool
+}
+
+func (l *linter) error(file string, line int, msg string) {
+	l.issues = append(l.issues, LintIssue{File: file, Line: line, Msg: msg})
+}
+
+func (l *linter) warn(file string, line int, msg string) {
+	l.issues = append(l.issues, LintIssue{File: file, Line: line, Msg: msg, Warning: true})
+}
+
+// hasErrors reports whether an error was found in file
+func (l *linter) hasErrors(file string) bool {
+	return slices.ContainsFunc(l.issues, func(i LintIssue) bool {
+		return i.File == file && !i.Warning
+	})
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (l *linter) lintManifest() {
+	m := l.pack.Manifest
+	if _, ok := DefaultTypes().Lookup(m.Name); ok {
+		l.warn(PackManifestFile, 0, fmt.Sprintf("name %q replaces the built-in project type", m.Name))
+	}
+	for _, pattern := range m.Delete {
+		if _, err := path.Match(pattern, ""); err != nil {
+			l.error(PackManifestFile, 0, fmt.Sprintf("delete %q: %v", pattern, err))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range m.Variables {
+		switch {
+		case !identifier.MatchString(v.Name):
+			l.error(PackManifestFile, 0, fmt.Sprintf("variable name %q is not an identifier, templates cannot refer to it as .Vars.%s", v.Name, v.Name))
+		case seen[v.Name]:
+			l.error(PackManifestFile, 0, fmt.Sprintf("variable %q is declared twice", v.Name))
+		case v.Required && v.Default != "":
+			l.warn(PackManifestFile, 0, fmt.Sprintf("variable %q is required but has a default, so it is never missing", v.Name))
+		}
+		seen[v.Name] = true
+	}
+}
+
+// lintFile checks the path and the content of a template
+func (l *linter) lintFile(f PackFile) {
+	file := PackFilesDir + "/" + f.Source
+	l.lintTemplate(file, f.Path, true)
+	l.lintTemplate(file, f.Content, false)
+}
+
+// lintTemplate parses text, a template or a templated path, and checks
+// its references
+func (l *linter) lintTemplate(file, text string, isPath bool) {
+	t, err := template.New(file).Funcs(FuncMap()).Parse(text)
+	if err != nil {
+		te := templateError(file, text, err)
+		if isPath {
+			l.error(file, 0, "path: "+te.message())
+		} else {
+			l.error(file, te.Line, te.message())
+		}
+		return
+	}
+	if t.Tree == nil {
+		return
+	}
+
+	w := &treeWalker{linter: l, file: file, tree: t.Tree, pathOnly: isPath, defined: t}
+	w.walk(t.Tree.Root, true)
+}
+
+// lintPaths renders every file path for sample data and checks that the
+// paths are valid, stay inside the project and do not collide
+func (l *linter) lintPaths() {
+	config := l.sampleConfig(nil)
+	seen := make(map[string]string)
+	folded := make(map[string]string)
+	for _, f := range l.pack.Files {
+		file := PackFilesDir + "/" + f.Source
+		p, err := renderPath(f.Path, config)
+		if err != nil {
+			// Ошибки разбора уже сообщены lintFile
+			if _, parseErr := template.New("").Funcs(FuncMap()).Parse(f.Path); parseErr == nil {
+				l.error(file, 0, "path: "+templateError(f.Path, f.Path, err).message())
+			}
+			continue
+		}
+
+		if msg := invalidPath(p); msg != "" {
+			l.error(file, 0, fmt.Sprintf("path %q %s", p, msg))
+			continue
+		}
+		if p == ManifestFile {
+			l.error(file, 0, fmt.Sprintf("path %q is reserved for the project manifest", p))
+			continue
+		}
+		if other, ok := seen[p]; ok {
+			l.error(file, 0, fmt.Sprintf("renders to %s, as %s/%s does", p, PackFilesDir, other))
+			continue
+		}
+		if other, ok := folded[strings.ToLower(p)]; ok {
+			l.warn(file, 0, fmt.Sprintf("renders to %s, which differs from %s/%s only in case", p, PackFilesDir, other))
+		}
+		seen[p] = f.Source
+		folded[strings.ToLower(p)] = f.Source
+	}
+}
+
+// lintGo plans and renders the pack for sample data, once without features
+// and once with all features it supports, and type-checks the Go files,
+// inherited ones included
+func (l *linter) lintGo() {
+	types, templates := DefaultTypes(), DefaultRegistry()
+	if err := l.pack.Register(templates, types); err != nil {
+		// Ошибки разбора файлов пакета уже сообщены lintFile
+		var te *TemplateError
+		if !errors.As(err, &te) || !strings.HasPrefix(te.Name, l.pack.TemplateName(PackFile{})) {
+			l.error(PackManifestFile, 0, err.Error())
+		}
+		return
+	}
+	l.registered = true
+	t, _ := types.Lookup(l.pack.Manifest.Name)
+	l.lintInheritance(t.(packType))
+	l.lintPatches(t.(packType), templates)
+
+	g := New(WithTypes(types), WithRegistry(templates))
+	for _, features := range [][]string{nil, l.supportedFeatures(types)} {
+		before := len(l.issues)
+		plan, err := g.Plan(l.sampleConfig(features))
+		if err != nil {
+			// Ошибки путей уже сообщены lintPaths
+			if !slices.ContainsFunc(l.issues, func(i LintIssue) bool { return !i.Warning }) {
+				l.error(PackManifestFile, 0, err.Error())
+			}
+			return
+		}
+
+		data := planData(plan)
+		var files []File
+		sources := make(map[string]string)
+		for _, f := range plan.Files {
+			source := l.source(f.Template)
+			content, err := g.renderTemplate(f.Template, data, g.partialScopes(plan.Config.ProjectType))
+			if err != nil {
+				var te *TemplateError
+				switch {
+				case strings.HasPrefix(source, PackFilesDir+"/") && errors.As(err, &te):
+					if !l.hasErrors(source) {
+						l.error(source, te.Line, te.message())
+					}
+				case source == "":
+					l.error(PackManifestFile, 0, fmt.Sprintf("inherited %s: %v", f.Path, err))
+				default:
+					l.error(source, 0, err.Error())
+				}
+				continue
+			}
+			if strings.HasSuffix(f.Path, ".go") {
+				content = formatGo(plan.Config.ModuleName, content)
+			}
+			files = append(files, File{Path: f.Path, Content: content})
+			sources[f.Path] = source
+		}
+
+		err = checkGo(plan.Config.ModuleName, files)
+		for _, e := range joined(err) {
+			var ce *CompileError
+			if !errors.As(e, &ce) {
+				l.error(PackManifestFile, 0, e.Error())
+				continue
+			}
+			msg := fmt.Sprintf("generated %s:%d:%d: %s", ce.Path, ce.Line, ce.Column, ce.Msg)
+			source := sources[ce.Path]
+			if source == "" {
+				source = PackManifestFile
+				msg = fmt.Sprintf("generated %s:%d:%d (inherited): %s", ce.Path, ce.Line, ce.Column, ce.Msg)
+			}
+			if features != nil {
+				msg += " (with features " + strings.Join(features, ", ") + ")"
+			}
+			l.error(source, 0, msg)
+		}
+		if len(l.issues) > before {
+			// С фичами те же ошибки повторились бы
+			return
+		}
+	}
+}
+
+// lintInheritance warns about deletes and patches of a pack that have no
+// effect on the files it inherits
+func (l *linter) lintInheritance(t packType) {
+	m := l.pack.Manifest
+	if t.base == nil {
+		if len(m.Delete) > 0 || len(m.Patches) > 0 {
+			l.warn(PackManifestFile, 0, "delete and patches have no effect without extends")
+		}
+		return
+	}
+
+	config := l.sampleConfig(nil)
+	var inherited []string
+	templates := make(map[string]bool)
+	for _, f := range t.base.Files(config) {
+		inherited = append(inherited, planPath(f.Path, config))
+		templates[f.Template] = true
+	}
+	for _, dir := range t.base.Directories(config) {
+		inherited = append(inherited, strings.TrimSuffix(dir, "/"))
+	}
+
+	for _, pattern := range m.Delete {
+		single := packType{pack: &Pack{Manifest: PackManifest{Delete: []string{pattern}}}}
+		if !slices.ContainsFunc(inherited, func(p string) bool { return single.deleted(p, config) }) {
+			l.warn(PackManifestFile, 0, fmt.Sprintf("delete %q matches no file or directory of %s", pattern, m.Extends))
+		}
+	}
+	for _, patch := range m.Patches {
+		if !templates[patch.Template] {
+			l.warn(PackManifestFile, 0, fmt.Sprintf("patch of %s has no effect, no file of %s uses the template", patch.Template, m.Extends))
+		}
+	}
+}
+
+// lintPatches checks the field and variable references of the patched
+// templates. Lines of the patched source mean nothing in the manifest, so
+// issues name the template instead.
+func (l *linter) lintPatches(t packType, templates *Registry) {
+	seen := make(map[string]bool)
+	for _, patch := range l.pack.Manifest.Patches {
+		if seen[patch.Template] {
+			continue
+		}
+		seen[patch.Template] = true
+
+		source, _ := templates.Lookup(t.patched[patch.Template])
+		parsed, err := template.New(patch.Template).Funcs(FuncMap()).Parse(source)
+		if err != nil || parsed.Tree == nil {
+			continue
+		}
+
+		before := len(l.issues)
+		w := &treeWalker{linter: l, file: PackManifestFile, tree: parsed.Tree}
+		w.walk(parsed.Tree.Root, true)
+		for i := before; i < len(l.issues); i++ {
+			l.issues[i].Line = 0
+			l.issues[i].Msg = "patch of " + patch.Template + ": " + l.issues[i].Msg
+		}
+	}
+}
+
+// source returns the pack file a template of a plan comes from: the
+// template file for the pack's own templates, the manifest for patched
+// ones and "" for inherited ones
+func (l *linter) source(template string) string {
+	if rest, ok := strings.CutPrefix(template, l.pack.TemplateName(PackFile{})); ok {
+		return PackFilesDir + "/" + rest
+	}
+	if strings.HasPrefix(template, l.pack.patchedName("")) {
+		return PackManifestFile
+	}
+	return ""
+}
+
+// supportedFeatures returns a set of features without conflicts the pack
+// supports, to render the templates with as many features as possible
+func (l *linter) supportedFeatures(types *TypeRegistry) []string {
+	root := types.Root(l.pack.Manifest.Name)
+	var features []string
+	for _, f := range DefaultFeatures().Names() {
+		if _, err := DefaultFeatures().Resolve(root, append(slices.Clone(features), f)); err == nil {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// joined splits an error created by errors.Join
+func joined(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		return j.Unwrap()
+	}
+	return []error{err}
+}
+
+// sampleConfig is the config the pack is rendered with while linting:
+// every variable is set to its default or "example"
+func (l *linter) sampleConfig(features []string) Config {
+	vars := make(map[string]string)
+	for _, v := range l.pack.Manifest.Variables {
+		vars[v.Name] = v.Default
+		if vars[v.Name] == "" {
+			vars[v.Name] = "example"
+		}
+	}
+	return Config{
+		ProjectName: "example",
+		ModuleName:  "example.com/example",
+		ProjectType: l.pack.Manifest.Name,
+		Features:    features,
+		Variables:   vars,
+	}
+}
+
+// treeWalker checks the field references and conditions of a template
+type treeWalker struct {
+	*linter
+	file     string
+	tree     *parse.Tree
+	pathOnly bool
+	// defined holds the templates the file defines itself
+	defined *template.Template
+}
+
+var templateDataType = reflect.TypeOf(TemplateData{})
+
+// pathData lists the fields available when rendering paths, see configData
+var pathData = []string{"ProjectName", "Module", "Type", "Features", "Vars", "HasFeature"}
+
+func (w *treeWalker) line(n parse.Node) int {
+	line, _ := w.tree.ErrorContext(n)
+	if _, rest, ok := strings.Cut(line, ":"); ok {
+		if n, err := strconv.Atoi(strings.SplitN(rest, ":", 2)[0]); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// walk visits n; root tells whether dot is the TemplateData at this point
+func (w *treeWalker) walk(n parse.Node, root bool) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			w.walk(c, root)
+		}
+	case *parse.ActionNode:
+		w.pipe(n.Pipe, root)
+	case *parse.IfNode:
+		w.condition(n.Pipe, root)
+		w.walk(n.List, root)
+		w.walk(n.ElseList, root)
+	case *parse.WithNode:
+		w.condition(n.Pipe, root)
+		w.walk(n.List, false)
+		w.walk(n.ElseList, root)
+	case *parse.RangeNode:
+		w.pipe(n.Pipe, root)
+		w.walk(n.List, false)
+		w.walk(n.ElseList, root)
+	case *parse.TemplateNode:
+		w.pipe(n.Pipe, root)
+		w.partial(n)
+	}
+}
+
+// partial checks that an included template is a partial or defined in the
+// file
+func (w *treeWalker) partial(n *parse.TemplateNode) {
+	if w.partials[n.Name] || (w.defined != nil && w.defined.Lookup(n.Name) != nil) {
+		return
+	}
+
+	var names []string
+	for name := range w.partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msg := fmt.Sprintf("unknown partial %q", n.Name)
+	if s := closest(n.Name, names); s != "" {
+		msg += fmt.Sprintf(", did you mean %q?", s)
+	}
+	w.error(w.file, w.line(n), msg)
+}
+
+func (w *treeWalker) pipe(p *parse.PipeNode, root bool) {
+	if p == nil {
+		return
+	}
+	for _, cmd := range p.Cmds {
+		w.command(cmd, root)
+	}
+}
+
+func (w *treeWalker) command(cmd *parse.CommandNode, root bool) {
+	// index .Vars "name"
+	if len(cmd.Args) == 3 {
+		if fn, ok := cmd.Args[0].(*parse.IdentifierNode); ok && fn.Ident == "index" {
+			if ident := w.rootIdent(cmd.Args[1], root); len(ident) == 1 && ident[0] == "Vars" {
+				if s, ok := cmd.Args[2].(*parse.StringNode); ok {
+					w.variable(cmd.Args[2], s.Text)
+				}
+			}
+		}
+	}
+
+	for _, arg := range cmd.Args {
+		switch arg := arg.(type) {
+		case *parse.PipeNode:
+			w.pipe(arg, root)
+		default:
+			if ident := w.rootIdent(arg, root); ident != nil {
+				w.reference(arg, ident)
+			}
+		}
+	}
+}
+
+// rootIdent returns the field chain of n if it starts at the TemplateData
+func (w *treeWalker) rootIdent(n parse.Node, root bool) []string {
+	switch n := n.(type) {
+	case *parse.FieldNode:
+		if root {
+			return n.Ident
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			return n.Ident[1:]
+		}
+	}
+	return nil
+}
+
+func (w *treeWalker) reference(n parse.Node, ident []string) {
+	name := ident[0]
+	_, isField := templateDataType.FieldByName(name)
+	_, isMethod := templateDataType.MethodByName(name)
+	switch {
+	case !isField && !isMethod:
+		msg := fmt.Sprintf("unknown field .%s", name)
+		if s := closest(name, templateFields()); s != "" {
+			msg += fmt.Sprintf(", did you mean .%s?", s)
+		}
+		w.error(w.file, w.line(n), msg)
+	case w.pathOnly && !slices.Contains(pathData, name):
+		w.error(w.file, 0, fmt.Sprintf("path: .%s is not available in file paths", name))
+	case name == "Vars" && len(ident) > 1:
+		w.variable(n, ident[1])
+	}
+}
+
+func (w *treeWalker) variable(n parse.Node, name string) {
+	for _, v := range w.pack.Manifest.Variables {
+		if v.Name == name {
+			w.used[name] = true
+			return
+		}
+	}
+
+	var names []string
+	for _, v := range w.pack.Manifest.Variables {
+		names = append(names, v.Name)
+	}
+	msg := fmt.Sprintf("variable %q is not declared in %s", name, PackManifestFile)
+	if s := closest(name, names); s != "" {
+		msg += fmt.Sprintf(", did you mean %q?", s)
+	}
+	w.error(w.file, w.line(n), msg)
+}
+
+// condition checks the pipeline of an if or with action
+func (w *treeWalker) condition(p *parse.PipeNode, root bool) {
+	w.pipe(p, root)
+	if p == nil || len(p.Cmds) != 1 || len(p.Decl) > 0 {
+		return
+	}
+
+	cmd := p.Cmds[0]
+	switch arg := cmd.Args[0].(type) {
+	case *parse.BoolNode, *parse.StringNode, *parse.NumberNode, *parse.NilNode:
+		if len(cmd.Args) == 1 {
+			w.warn(w.file, w.line(p), fmt.Sprintf("condition %s is constant", p))
+		}
+	case *parse.IdentifierNode:
+		args := len(cmd.Args) - 1
+		if want, ok := comparisonArgs[arg.Ident]; ok && !want(args) {
+			w.error(w.file, w.line(p), fmt.Sprintf("condition %s: wrong number of arguments for %s: %d", p, arg.Ident, args))
+		}
+	case *parse.FieldNode:
+		// .HasFeature "name" должна ссылаться на существующую фичу
+		if root && len(arg.Ident) == 1 && arg.Ident[0] == "HasFeature" && len(cmd.Args) == 2 {
+			if s, ok := cmd.Args[1].(*parse.StringNode); ok {
+				if _, known := DefaultFeatures().Lookup(s.Text); !known {
+					w.warn(w.file, w.line(p), fmt.Sprintf("condition %s: unknown feature %q", p, s.Text))
+				}
+			}
+		}
+	}
+}
+
+// comparisonArgs checks the argument count of the builtin functions used
+// in conditions
+var comparisonArgs = map[string]func(n int) bool{
+	"eq":  func(n int) bool { return n >= 2 },
+	"ne":  func(n int) bool { return n == 2 },
+	"lt":  func(n int) bool { return n == 2 },
+	"le":  func(n int) bool { return n == 2 },
+	"gt":  func(n int) bool { return n == 2 },
+	"ge":  func(n int) bool { return n == 2 },
+	"not": func(n int) bool { return n == 1 },
+	"and": func(n int) bool { return n >= 1 },
+	"or":  func(n int) bool { return n >= 1 },
+}
+
+// templateFields returns the names of the fields and methods of TemplateData
+func templateFields() []string {
+	var names []string
+	for i := range templateDataType.NumField() {
+		names = append(names, templateDataType.Field(i).Name)
+	}
+	for i := range templateDataType.NumMethod() {
+		names = append(names, templateDataType.Method(i).Name)
+	}
+	return names
+}
@@ -0,0 +1,341 @@
+package ginit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// ManifestFile is written into every generated project to remember how it
+// was generated. Status and Upgrade rely on it.
+const ManifestFile = ".ginit.json"
+
+// ErrNoManifest is returned when a directory was not generated by ginit
+var ErrNoManifest = errors.New("no " + ManifestFile + " found, the project was not generated by ginit")
+
+// Manifest records the config of a generated project and the checksums of
+// the files rendered from templates, as they were generated
+type Manifest struct {
+	Project  string   `json:"project"`
+	Module   string   `json:"module"`
+	Type     string   `json:"type"`
+	Features []string `json:"features,omitempty"`
+	// Variables are the template variables, including defaults
+	Variables map[string]string `json:"variables,omitempty"`
+	VCS       bool              `json:"vcs"`
+	// Files maps project-relative paths to SHA-256 of the generated content
+	Files map[string]string `json:"files"`
+}
+
+func newManifest(rendered *Rendered) *Manifest {
+	c := rendered.Plan.Config
+	m := &Manifest{
+		Project:   c.ProjectName,
+		Module:    c.ModuleName,
+		Type:      c.ProjectType,
+		Features:  c.Features,
+		Variables: c.Variables,
+		VCS:       c.InitVCS,
+		Files:     make(map[string]string),
+	}
+	for _, f := range rendered.Files {
+		m.Files[f.Path] = checksum(f.Content)
+	}
+	return m
+}
+
+// Config returns the config the project in dir was generated with
+func (m *Manifest) Config(dir string) Config {
+	return Config{
+		ProjectName: m.Project,
+		ModuleName:  m.Module,
+		Directory:   dir,
+		ProjectType: m.Type,
+		Features:    m.Features,
+		Variables:   m.Variables,
+		InitVCS:     m.VCS,
+	}
+}
+
+// ReadManifest reads the manifest of the project in dir
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoManifest
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]string)
+	}
+	return &m, nil
+}
+
+func (m *Manifest) encode() []byte {
+	data, _ := json.MarshalIndent(m, "", "  ")
+	return append(data, '\n')
+}
+
+// FileState describes a generated file compared with its manifest checksum
+type FileState string
+
+const (
+	FileUnchanged FileState = "unchanged"
+	FileModified  FileState = "modified"
+	FileMissing   FileState = "missing"
+)
+
+type FileStatus struct {
+	Path  string
+	State FileState
+}
+
+// Status compares the files of the project in dir with the checksums
+// recorded when it was generated. Files are sorted by path.
+func Status(dir string) (*Manifest, []FileStatus, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var statuses []FileStatus
+	for _, path := range sortedKeys(m.Files) {
+		sum, err := fileChecksum(filepath.Join(dir, filepath.FromSlash(path)))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			statuses = append(statuses, FileStatus{path, FileMissing})
+		case err != nil:
+			return nil, nil, err
+		case sum == m.Files[path]:
+			statuses = append(statuses, FileStatus{path, FileUnchanged})
+		default:
+			statuses = append(statuses, FileStatus{path, FileModified})
+		}
+	}
+	return m, statuses, nil
+}
+
+// UpgradeAction is what Upgrade does (or would do) with a file
+type UpgradeAction string
+
+const (
+	// UpgradeCreate: the template adds a new file
+	UpgradeCreate UpgradeAction = "create"
+	// UpgradeUpdate: the file was not modified locally and the template changed
+	UpgradeUpdate UpgradeAction = "update"
+	// UpgradeUnchanged: the file already matches the template
+	UpgradeUnchanged UpgradeAction = "unchanged"
+	// UpgradeConflict: both the file and the template changed, the file is kept
+	UpgradeConflict UpgradeAction = "conflict"
+	// UpgradeSkipDeleted: the file was deleted locally and is not restored
+	UpgradeSkipDeleted UpgradeAction = "skip-deleted"
+	// UpgradeObsolete: the template no longer produces the file, it is kept
+	UpgradeObsolete UpgradeAction = "obsolete"
+)
+
+type FileUpgrade struct {
+	Path   string
+	Action UpgradeAction
+}
+
+// Upgrade re-renders the project in dir with the current templates and
+// updates every file that was not modified since it was generated. Locally
+// modified files are reported as conflicts and left alone. With dryRun
+// nothing is written.
+func (g *Generator) Upgrade(dir string, dryRun bool) ([]FileUpgrade, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	return g.sync(context.Background(), dir, m, m.Config(dir), false, dryRun)
+}
+
+// AddFeatures adds features to the project in dir. New files are created
+// and files the features change are updated the same way Upgrade does.
+// If any of them was modified locally, nothing is written and the
+// changes are returned with a *ConflictError. Dependencies of the
+// features are added with go get.
+func (g *Generator) AddFeatures(ctx context.Context, dir string, features []string, dryRun bool) ([]FileUpgrade, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	config := m.Config(dir)
+	for _, name := range features {
+		if slices.Contains(config.Features, name) {
+			return nil, &ConfigError{Field: "feature", Value: name, Reason: "already added"}
+		}
+		config.Features = append(config.Features, name)
+	}
+	return g.sync(ctx, dir, m, config, true, dryRun)
+}
+
+// sync re-renders the project in dir with config and applies the changes
+// to files that were not modified since m was written. With strict a
+// conflict in any file leaves all files untouched.
+func (g *Generator) sync(ctx context.Context, dir string, m *Manifest, config Config, strict, dryRun bool) ([]FileUpgrade, error) {
+	plan, err := g.Plan(config)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := g.Render(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	next := newManifest(rendered)
+	var changes []FileUpgrade
+	var writes []File
+	var conflicts []string
+
+	for _, f := range rendered.Files {
+		recorded, known := m.Files[f.Path]
+		current, err := fileChecksum(filepath.Join(dir, filepath.FromSlash(f.Path)))
+
+		var action UpgradeAction
+		switch {
+		case errors.Is(err, os.ErrNotExist) && known:
+			action = UpgradeSkipDeleted
+			delete(next.Files, f.Path)
+		case errors.Is(err, os.ErrNotExist):
+			action = UpgradeCreate
+			writes = append(writes, f)
+		case err != nil:
+			return nil, err
+		case current == next.Files[f.Path]:
+			action = UpgradeUnchanged
+		case known && current == recorded:
+			action = UpgradeUpdate
+			writes = append(writes, f)
+		default:
+			action = UpgradeConflict
+			conflicts = append(conflicts, f.Path)
+			if known {
+				next.Files[f.Path] = recorded
+			} else {
+				delete(next.Files, f.Path)
+			}
+		}
+		changes = append(changes, FileUpgrade{f.Path, action})
+	}
+
+	for _, path := range sortedKeys(m.Files) {
+		if !planned(rendered, path) {
+			changes = append(changes, FileUpgrade{path, UpgradeObsolete})
+		}
+	}
+
+	if strict && len(conflicts) > 0 {
+		return changes, &ConflictError{Paths: conflicts}
+	}
+	if dryRun {
+		return changes, nil
+	}
+	if err := rendered.insideDir(dir, append([]string{"go.mod", "go.sum", ManifestFile}, plan.Directories...), writes); err != nil {
+		return changes, err
+	}
+
+	for _, d := range plan.Directories {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(d)), 0755); err != nil {
+			return changes, err
+		}
+	}
+
+	for _, f := range writes {
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return changes, err
+		}
+		if err := os.WriteFile(path, f.Content, 0644); err != nil {
+			return changes, err
+		}
+		g.log.Debug("file written", "path", f.Path)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), next.encode(), 0644); err != nil {
+		return changes, err
+	}
+
+	return changes, g.addMissingDependencies(ctx, dir, plan)
+}
+
+// addMissingDependencies runs go get for the dependencies of plan that are
+// not required by go.mod yet
+func (g *Generator) addMissingDependencies(ctx context.Context, dir string, plan *Plan) error {
+	required, err := requiredVersions(filepath.Join(dir, "go.mod"), plan.Dependencies)
+	if err != nil {
+		return fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	var missing []string
+	for _, dep := range plan.Dependencies {
+		if !slices.ContainsFunc(required, func(d Dependency) bool { return d.Path == dep }) {
+			missing = append(missing, dep)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		g.log.Warn("go not found in PATH, add the dependencies with go get", "dependencies", missing)
+		return nil
+	}
+
+	config := plan.Config
+	config.Directory = dir
+	a := &applier{ctx: ctx, config: config, reporter: g.reporter, log: g.log, env: g.env, result: newResult(config)}
+	for _, dep := range missing {
+		if err := a.runCommand("go", "get", dep); err != nil {
+			return fmt.Errorf("failed to add dependency %s: %w", dep, err)
+		}
+	}
+	return nil
+}
+
+func planned(rendered *Rendered, path string) bool {
+	for _, f := range rendered.Files {
+		if f.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func fileChecksum(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	// Git on Windows may convert line endings on checkout
+	return checksum(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
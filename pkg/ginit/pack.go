@@ -0,0 +1,374 @@
+package ginit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PackManifestFile is the manifest of a template pack directory
+const PackManifestFile = "ginit-template.json"
+
+// PackFilesDir is the directory of a template pack holding the project
+// files. Each file is a template; its path, relative to the directory, is a
+// template too and a ".tmpl" suffix is dropped.
+const PackFilesDir = "files"
+
+// PackPartialsDir is the optional directory of a template pack holding
+// partials: partials/bootstrap.tmpl is included as {{template "bootstrap" .}}
+// by the templates of the pack and, replacing the built-in partial, by the
+// inherited ones. Other project types never see the partials of a pack.
+const PackPartialsDir = "partials"
+
+// PackManifest describes a template pack: a custom project type defined by
+// a directory of templates instead of Go code
+type PackManifest struct {
+	// Name is the project type name, e.g. "company-service"
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	// GoVersion is the oldest Go release the generated code supports
+	GoVersion    string             `json:"go_version,omitempty"`
+	Variables    []TemplateVariable `json:"variables,omitempty"`
+	Dependencies []string           `json:"dependencies,omitempty"`
+	// NextSteps default to the steps of the base type, or go mod tidy and
+	// go build ./...
+	NextSteps []string `json:"next_steps,omitempty"`
+
+	// Extends names a project type, e.g. "web", whose directories, files
+	// and dependencies the pack inherits. Files of the pack replace
+	// inherited files with the same path.
+	Extends string `json:"extends,omitempty"`
+	// Delete lists inherited files and directories to leave out. Entries
+	// are templates like file paths and may be path.Match patterns.
+	Delete []string `json:"delete,omitempty"`
+	// Patches edit the templates of inherited files
+	Patches []PackPatch `json:"patches,omitempty"`
+}
+
+// PackPatch replaces text in the source of an inherited template, as
+// listed by 'ginit templates list'. Find must occur exactly once, so that
+// a patch that no longer fits the base template is reported instead of
+// silently doing nothing.
+type PackPatch struct {
+	Template string `json:"template"`
+	Find     string `json:"find"`
+	Replace  string `json:"replace"`
+}
+
+// Pack is a template pack loaded from disk
+type Pack struct {
+	Dir      string
+	Manifest PackManifest
+	Files    []PackFile
+	// Partials have the partial name as Path
+	Partials []PackFile
+}
+
+// PackFile is a template of a pack
+type PackFile struct {
+	// Path is the templated project path, e.g. cmd/{{.ProjectName}}/main.go
+	Path string
+	// Source is the path of the template relative to the pack's files
+	// directory, with forward slashes
+	Source  string
+	Content string
+}
+
+// LoadPack reads the template pack in dir
+func LoadPack(dir string) (*Pack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PackManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("template pack %s: %w", dir, err)
+	}
+
+	p := &Pack{Dir: dir}
+	if err := json.Unmarshal(data, &p.Manifest); err != nil {
+		return nil, fmt.Errorf("template pack %s: invalid %s: %w", dir, PackManifestFile, err)
+	}
+	if p.Manifest.Name == "" {
+		return nil, fmt.Errorf("template pack %s: %s has no name", dir, PackManifestFile)
+	}
+
+	p.Files, err = readPackDir(dir, PackFilesDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("template pack %s: no %s directory", dir, PackFilesDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("template pack %s: %w", dir, err)
+	}
+
+	p.Partials, err = readPackDir(dir, PackPartialsDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("template pack %s: %w", dir, err)
+	}
+	return p, nil
+}
+
+// readPackDir reads the templates below the subdirectory sub of the pack
+// in dir. Symlinks must point to files inside the pack.
+func readPackDir(dir, sub string) ([]PackFile, error) {
+	root := filepath.Join(dir, sub)
+	var files []PackFile
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			if err := packLink(dir, path); err != nil {
+				return err
+			}
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		files = append(files, PackFile{
+			Path:    strings.TrimSuffix(rel, ".tmpl"),
+			Source:  rel,
+			Content: string(content),
+		})
+		return nil
+	})
+	return files, err
+}
+
+// packLink checks that the symlink at path resolves to a file inside the
+// pack in dir, so that a pack cannot copy e.g. ~/.ssh into projects
+func packLink(dir, path string) error {
+	rel, _ := filepath.Rel(dir, path)
+	rel = filepath.ToSlash(rel)
+	link, _ := os.Readlink(path)
+
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("%s is a symlink to %s, which does not exist", rel, link)
+	}
+	if !within(root, target) {
+		return fmt.Errorf("%s is a symlink to %s, outside the pack", rel, link)
+	}
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a symlink to a directory, which packs cannot contain", rel)
+	}
+	return nil
+}
+
+// TemplateName returns the registry name of a file of the pack
+func (p *Pack) TemplateName(f PackFile) string {
+	return p.scope() + "files/" + f.Source
+}
+
+// scope returns the prefix of the registry names of the pack's templates
+func (p *Pack) scope() string {
+	return "packs/" + p.Manifest.Name + "/"
+}
+
+// patchedName returns the registry name of an inherited template patched
+// by the pack
+func (p *Pack) patchedName(template string) string {
+	return p.scope() + "patches/" + template
+}
+
+// Register adds the templates of the pack to templates and the pack as a
+// project type to types. The base type of a pack that extends another
+// must already be registered in types, its templates in templates.
+func (p *Pack) Register(templates *Registry, types *TypeRegistry) error {
+	t := packType{pack: p, patched: make(map[string]string)}
+	if p.Manifest.Extends != "" {
+		if err := types.Check(p.Manifest.Extends); err != nil {
+			return fmt.Errorf("template pack %s: extends: %w", p.Manifest.Name, err)
+		}
+		t.base, _ = types.Lookup(p.Manifest.Extends)
+	}
+
+	for _, patch := range p.Manifest.Patches {
+		name := patch.Template
+		if patched, ok := t.patched[name]; ok {
+			// Несколько патчей одного шаблона применяются по очереди
+			name = patched
+		}
+		source, ok := templates.Lookup(name)
+		if !ok {
+			return fmt.Errorf("template pack %s: patch: %w", p.Manifest.Name, &TemplateError{Name: patch.Template, Err: ErrTemplateNotFound})
+		}
+		if n := strings.Count(source, patch.Find); patch.Find == "" || n != 1 {
+			return fmt.Errorf("template pack %s: patch of %s: find text occurs %d times, want exactly once", p.Manifest.Name, patch.Template, n)
+		}
+		name = p.patchedName(patch.Template)
+		if err := templates.Register(name, strings.Replace(source, patch.Find, patch.Replace, 1)); err != nil {
+			return fmt.Errorf("template pack %s: patch of %s: %w", p.Manifest.Name, patch.Template, err)
+		}
+		t.patched[patch.Template] = name
+	}
+
+	for _, f := range p.Partials {
+		// Встроенные партиалы не заменяются: партиалы пакета видны только его проектам
+		if err := templates.Register(p.scope()+PartialPrefix+f.Path, f.Content); err != nil {
+			return err
+		}
+	}
+	for _, f := range p.Files {
+		if err := templates.Register(p.TemplateName(f), f.Content); err != nil {
+			return err
+		}
+	}
+	return types.Register(t)
+}
+
+// partialScopes returns the scopes of the pack partials visible to projects
+// of type t, see Registry.Partials: those of the packs t is built from, the
+// extended ones first
+func partialScopes(t ProjectType) []string {
+	var scopes []string
+	for t != nil {
+		pt, ok := t.(packType)
+		if !ok {
+			break
+		}
+		scopes = append([]string{pt.pack.scope()}, scopes...)
+		t = pt.base
+	}
+	return scopes
+}
+
+// packType is the project type of a template pack
+type packType struct {
+	pack *Pack
+	// base is the extended type, nil if the pack extends none
+	base ProjectType
+	// patched maps inherited template names to their patched versions
+	patched map[string]string
+}
+
+func (t packType) Info() TypeInfo {
+	info := TypeInfo{
+		Name:        t.pack.Manifest.Name,
+		Title:       t.pack.Manifest.Name,
+		Description: t.pack.Manifest.Description,
+	}
+	if info.Description == "" && t.base != nil {
+		info.Description = t.base.Info().Description
+	}
+	return info
+}
+
+func (t packType) Base() ProjectType {
+	return t.base
+}
+
+func (t packType) Directories(config Config) []string {
+	if t.base == nil {
+		return nil
+	}
+	var dirs []string
+	for _, dir := range t.base.Directories(config) {
+		if !t.deleted(strings.TrimSuffix(dir, "/"), config) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func (t packType) Files(config Config) []PlannedFile {
+	var files []PlannedFile
+	own := make(map[string]bool)
+	for _, f := range t.pack.Files {
+		own[planPath(f.Path, config)] = true
+	}
+
+	if t.base != nil {
+		for _, f := range t.base.Files(config) {
+			p := planPath(f.Path, config)
+			if own[p] || t.deleted(p, config) {
+				continue
+			}
+			if name, ok := t.patched[f.Template]; ok {
+				f.Template = name
+			}
+			files = append(files, f)
+		}
+	}
+
+	for _, f := range t.pack.Files {
+		files = append(files, PlannedFile{Path: f.Path, Template: t.pack.TemplateName(f)})
+	}
+	return files
+}
+
+// deleted reports whether the inherited file or directory p is left out
+// by the Delete list of the pack; deleting a directory leaves out
+// everything below it
+func (t packType) deleted(p string, config Config) bool {
+	for _, pattern := range t.pack.Manifest.Delete {
+		pattern = strings.TrimSuffix(planPath(pattern, config), "/")
+		for dir := p; dir != "."; dir = path.Dir(dir) {
+			if matched, _ := path.Match(pattern, dir); matched || pattern == dir {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// planPath renders a templated path for comparison; a path that fails to
+// render is returned as is, Plan reports the error
+func planPath(p string, config Config) string {
+	if rendered, err := renderPath(p, config); err == nil {
+		return rendered
+	}
+	return p
+}
+
+func (t packType) Dependencies(config Config) []string {
+	var deps []string
+	if t.base != nil {
+		deps = t.base.Dependencies(config)
+	}
+	return appendMissing(slices.Clone(deps), t.pack.Manifest.Dependencies...)
+}
+
+func (t packType) NextSteps(config Config) []string {
+	if len(t.pack.Manifest.NextSteps) > 0 {
+		return t.pack.Manifest.NextSteps
+	}
+	if t.base != nil {
+		return t.base.NextSteps(config)
+	}
+	return []string{"go mod tidy", "go build ./..."}
+}
+
+func (t packType) ReadmeSections(config Config) []ReadmeSection {
+	if t.base == nil {
+		return nil
+	}
+	return t.base.ReadmeSections(config)
+}
+
+// Variables returns the variables of the pack and those of the base type
+// the pack does not redeclare
+func (t packType) Variables() []TemplateVariable {
+	vars := slices.Clone(t.pack.Manifest.Variables)
+	if vt, ok := t.base.(VariableType); ok {
+		for _, v := range vt.Variables() {
+			if !slices.ContainsFunc(vars, func(own TemplateVariable) bool { return own.Name == v.Name }) {
+				vars = append(vars, v)
+			}
+		}
+	}
+	return vars
+}
@@ -0,0 +1,205 @@
+package ginit
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Plan is the resolved description of a project: what Apply will create
+// and run. It can be inspected or adjusted before rendering.
+type Plan struct {
+	Config       Config
+	Directories  []string
+	Files        []PlannedFile
+	Dependencies []string
+	// NextSteps are the commands to run after generation, starting with
+	// cd into the project directory
+	NextSteps      []string
+	ReadmeSections []ReadmeSection
+	// ConfigFields and Snippets are contributed by features
+	ConfigFields []ConfigField
+	Snippets     []Snippet
+}
+
+// PlannedFile is a project file rendered from a registry template
+type PlannedFile struct {
+	// Path is relative to Config.Directory and uses forward slashes
+	Path     string
+	Template string
+}
+
+// Plan resolves config into the directories, files and dependencies of
+// the project. It does not touch the filesystem. Plan.Config.Features
+// lists the resolved features, including required ones. Plan returns a
+// *PolicyError if the project does not comply with the Generator's policy.
+func (g *Generator) Plan(config Config) (*Plan, error) {
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if err := g.types.Check(config.ProjectType); err != nil {
+		return nil, err
+	}
+	t, _ := g.types.Lookup(config.ProjectType)
+	config, err := withVariables(t, config)
+	if err != nil {
+		return nil, err
+	}
+
+	features, err := g.features.Resolve(g.types.Root(config.ProjectType), config.Features)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		Config:         config,
+		Directories:    t.Directories(config),
+		Files:          t.Files(config),
+		Dependencies:   t.Dependencies(config),
+		NextSteps:      append([]string{"cd " + config.Directory}, t.NextSteps(config)...),
+		ReadmeSections: t.ReadmeSections(config),
+	}
+	if !plan.hasFile("README.md") {
+		plan.Files = append(plan.Files, PlannedFile{"README.md", "README.md"})
+	}
+	if err := plan.addFeatures(features); err != nil {
+		return nil, err
+	}
+	if err := g.policy.Check(plan.Config); err != nil {
+		return nil, err
+	}
+
+	if config.InitVCS {
+		if !plan.hasFile(".gitignore") {
+			plan.Files = append(plan.Files, PlannedFile{".gitignore", "gitignore"})
+		}
+		plan.NextSteps = append(plan.NextSteps, "git add .", "git commit -m \"Initial commit\"")
+	}
+
+	for _, dir := range plan.Directories {
+		if msg := invalidPath(strings.TrimSuffix(dir, "/")); msg != "" {
+			return nil, &TemplateError{Path: dir, Err: errors.New("directory " + msg)}
+		}
+	}
+	for i, f := range plan.Files {
+		if _, ok := g.registry.Lookup(f.Template); !ok {
+			return nil, &TemplateError{Name: f.Template, Path: f.Path, Err: ErrTemplateNotFound}
+		}
+		path, err := renderPath(f.Path, plan.Config)
+		if err != nil {
+			return nil, &TemplateError{Name: f.Template, Path: f.Path, Err: err}
+		}
+		// Путь из шаблона или переменной не должен выходить из проекта
+		if msg := invalidPath(path); msg != "" {
+			return nil, &TemplateError{Name: f.Template, Path: path, Err: errors.New("path " + msg)}
+		}
+		plan.Files[i].Path = path
+	}
+
+	return plan, nil
+}
+
+func (p *Plan) hasFile(path string) bool {
+	for _, f := range p.Files {
+		if f.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// renderPath executes path as a template when it contains an action, so
+// project types can name files after the project: cmd/{{.ProjectName}}/main.go
+func renderPath(path string, config Config) (string, error) {
+	if !strings.Contains(path, "{{") {
+		return path, nil
+	}
+
+	t, err := template.New(path).Funcs(FuncMap()).Option("missingkey=error").Parse(path)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, configData(config)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// invalidPath explains why p cannot be a project file path, or returns ""
+func invalidPath(p string) string {
+	switch {
+	case p == "":
+		return "is empty"
+	case strings.HasPrefix(p, "/") || (len(p) > 1 && p[1] == ':'):
+		return "is absolute"
+	case strings.Contains(p, `\`):
+		return "contains a backslash, use forward slashes"
+	case strings.HasSuffix(p, "/"):
+		return "names a directory"
+	}
+	for _, elem := range strings.Split(p, "/") {
+		switch elem {
+		case "":
+			return "has an empty element"
+		case ".", "..":
+			return "must not contain . or .. elements"
+		}
+	}
+	return ""
+}
+
+// withVariables applies the defaults of the variables t declares and
+// rejects unknown variables and missing required ones
+func withVariables(t ProjectType, config Config) (Config, error) {
+	var declared []TemplateVariable
+	if vt, ok := t.(VariableType); ok {
+		declared = vt.Variables()
+	}
+
+	vars := maps.Clone(config.Variables)
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	var names []string
+	for _, v := range declared {
+		names = append(names, v.Name)
+		if _, ok := vars[v.Name]; !ok && v.Default != "" {
+			vars[v.Name] = v.Default
+		}
+		if v.Required && vars[v.Name] == "" {
+			return config, &ConfigError{Field: "variable", Value: v.Name, Reason: fmt.Sprintf("is required by the %s project type", config.ProjectType)}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.Variables)) {
+		if slices.Contains(names, name) {
+			continue
+		}
+		reason := fmt.Sprintf("is not declared by the %s project type", config.ProjectType)
+		if s := closest(name, names); s != "" {
+			reason += fmt.Sprintf(", did you mean %q?", s)
+		}
+		return config, &ConfigError{Field: "variable", Value: name, Reason: reason}
+	}
+
+	if len(vars) == 0 {
+		vars = nil
+	}
+	config.Variables = vars
+	return config, nil
+}
+
+// Steps returns the titles of the steps Apply runs for this plan, in
+// order, so a UI can render the whole checklist before generation starts.
+func (p *Plan) Steps() []string {
+	var titles []string
+	for _, s := range applySteps(p.Config) {
+		titles = append(titles, s.title)
+	}
+	return titles
+}
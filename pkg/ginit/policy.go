@@ -0,0 +1,149 @@
+package ginit
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Policy holds the conventions of an organisation that generated projects
+// must follow. It is part of the settings file, usually an included file
+// shared by the organisation, and enforced by Generator.Plan when set with
+// WithPolicy.
+type Policy struct {
+	// Name identifies the policy in explanations, e.g. "acme"
+	Name string `json:"name,omitempty"`
+	// RequiredFeatures must be enabled, directly or as a requirement of
+	// another feature
+	RequiredFeatures []string `json:"required_features,omitempty"`
+	// ForbiddenFeatures must not be enabled
+	ForbiddenFeatures []string `json:"forbidden_features,omitempty"`
+	// ModulePrefixes lists the allowed module path prefixes, e.g.
+	// "github.com/myorg/"; any module is allowed if empty
+	ModulePrefixes []string `json:"module_prefixes,omitempty"`
+	// NamePattern is a regular expression project names must match
+	NamePattern string `json:"name_pattern,omitempty"`
+
+	// namePatterns are the patterns of all merged policies
+	namePatterns []string
+}
+
+// merge returns the policy extended with other. Feature lists are joined
+// and a project must satisfy the module prefixes and name patterns of
+// both, so a file including a policy cannot loosen it. Prefixes without a
+// module in common are an error.
+func (p *Policy) merge(other *Policy) (*Policy, error) {
+	if p == nil {
+		return other, nil
+	}
+	if other == nil {
+		return p, nil
+	}
+
+	merged := *p
+	if other.Name != "" {
+		merged.Name = other.Name
+	}
+	merged.RequiredFeatures = appendMissing(slices.Clone(p.RequiredFeatures), other.RequiredFeatures...)
+	merged.ForbiddenFeatures = appendMissing(slices.Clone(p.ForbiddenFeatures), other.ForbiddenFeatures...)
+
+	prefixes, ok := intersectPrefixes(p.ModulePrefixes, other.ModulePrefixes)
+	if !ok {
+		return nil, fmt.Errorf("policy module prefixes %s and %s allow no module in common",
+			strings.Join(p.ModulePrefixes, ", "), strings.Join(other.ModulePrefixes, ", "))
+	}
+	merged.ModulePrefixes = prefixes
+
+	if merged.NamePattern == "" {
+		merged.NamePattern = other.NamePattern
+	}
+	merged.namePatterns = appendMissing(p.NamePatterns(), other.NamePatterns()...)
+	return &merged, nil
+}
+
+// NamePatterns returns the regular expressions project names must match:
+// NamePattern and the patterns of merged policies
+func (p *Policy) NamePatterns() []string {
+	if len(p.namePatterns) > 0 {
+		return slices.Clone(p.namePatterns)
+	}
+	if p.NamePattern == "" {
+		return nil
+	}
+	return []string{p.NamePattern}
+}
+
+// intersectPrefixes returns the module prefixes allowed by both a and b:
+// of two prefixes where one extends the other, the longer one. An empty
+// list allows any module; ok is false if a and b allow no module in
+// common.
+func intersectPrefixes(a, b []string) (prefixes []string, ok bool) {
+	if len(a) == 0 {
+		return b, true
+	}
+	if len(b) == 0 {
+		return a, true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case strings.HasPrefix(x, y):
+				prefixes = appendMissing(prefixes, x)
+			case strings.HasPrefix(y, x):
+				prefixes = appendMissing(prefixes, y)
+			}
+		}
+	}
+	return prefixes, len(prefixes) > 0
+}
+
+func appendMissing(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Check returns a *PolicyError listing every rule config breaks. Features
+// are checked as given, so config.Features should include required
+// features, as Plan.Config.Features does.
+func (p *Policy) Check(config Config) error {
+	if p == nil {
+		return nil
+	}
+
+	var violations []string
+	for _, f := range p.RequiredFeatures {
+		if !slices.Contains(config.Features, f) {
+			violations = append(violations, fmt.Sprintf("feature %q is required", f))
+		}
+	}
+	for _, f := range p.ForbiddenFeatures {
+		if slices.Contains(config.Features, f) {
+			violations = append(violations, fmt.Sprintf("feature %q is not allowed", f))
+		}
+	}
+
+	if len(p.ModulePrefixes) > 0 && !slices.ContainsFunc(p.ModulePrefixes, func(prefix string) bool {
+		return strings.HasPrefix(config.ModuleName, prefix)
+	}) {
+		violations = append(violations, fmt.Sprintf("module %q must start with %s", config.ModuleName, strings.Join(p.ModulePrefixes, " or ")))
+	}
+
+	for _, pattern := range p.NamePatterns() {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("name pattern %q is invalid: %v", pattern, err))
+		} else if !re.MatchString(config.ProjectName) {
+			violations = append(violations, fmt.Sprintf("project name %q must match %s", config.ProjectName, pattern))
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &PolicyError{Policy: p.Name, Violations: violations}
+}
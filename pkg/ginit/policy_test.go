@@ -0,0 +1,111 @@
+package ginit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSettings writes the settings files to a temporary directory and
+// returns the path of the first one
+func writeSettings(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "config.json")
+}
+
+func TestIncludedPolicyCannotBeLoosened(t *testing.T) {
+	path := writeSettings(t, map[string]string{
+		"org.json": `{"policy": {
+			"name": "org",
+			"module_prefixes": ["github.com/org/"],
+			"name_pattern": "^svc-"
+		}}`,
+		"config.json": `{"include": ["org.json"], "policy": {
+			"module_prefixes": ["github.com/", "gitlab.com/me/"],
+			"name_pattern": "^[a-z-]+$"
+		}}`,
+	})
+
+	settings, err := LoadSettings(path)
+	if err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	policy := settings.Policy
+
+	tests := []struct {
+		name       string
+		project    string
+		module     string
+		violations []string
+	}{
+		{"both satisfied", "svc-api", "github.com/org/svc-api", nil},
+		{"user prefix only", "svc-api", "github.com/me/svc-api", []string{"module"}},
+		{"other user prefix", "svc-api", "gitlab.com/me/svc-api", []string{"module"}},
+		{"user pattern only", "api", "github.com/org/api", []string{"^svc-"}},
+		{"org pattern only", "svc-API", "github.com/org/svc-api", []string{"^[a-z-]+$"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(Config{ProjectName: tt.project, ModuleName: tt.module})
+			if tt.violations == nil {
+				if err != nil {
+					t.Fatalf("Check: %v", err)
+				}
+				return
+			}
+
+			var pe *PolicyError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Check = %v, want a PolicyError", err)
+			}
+			if len(pe.Violations) != len(tt.violations) {
+				t.Fatalf("violations = %q, want %d", pe.Violations, len(tt.violations))
+			}
+			for i, want := range tt.violations {
+				if !strings.Contains(pe.Violations[i], want) {
+					t.Errorf("violation %q does not mention %q", pe.Violations[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyPrefixesWithoutCommonModule(t *testing.T) {
+	path := writeSettings(t, map[string]string{
+		"org.json":    `{"policy": {"module_prefixes": ["github.com/org/"]}}`,
+		"config.json": `{"include": ["org.json"], "policy": {"module_prefixes": ["gitlab.com/"]}}`,
+	})
+
+	if _, err := LoadSettings(path); err == nil || !strings.Contains(err.Error(), "no module in common") {
+		t.Fatalf("LoadSettings = %v, want an error about the prefixes", err)
+	}
+}
+
+func TestIntersectPrefixes(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want []string
+		ok   bool
+	}{
+		{nil, nil, nil, true},
+		{[]string{"github.com/org/"}, nil, []string{"github.com/org/"}, true},
+		{nil, []string{"github.com/org/"}, []string{"github.com/org/"}, true},
+		{[]string{"github.com/"}, []string{"github.com/org/"}, []string{"github.com/org/"}, true},
+		{[]string{"github.com/org/team/", "gitlab.com/"}, []string{"github.com/org/"}, []string{"github.com/org/team/"}, true},
+		{[]string{"github.com/org/"}, []string{"gitlab.com/org/"}, nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := intersectPrefixes(tt.a, tt.b)
+		if ok != tt.ok || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("intersectPrefixes(%q, %q) = %q, %v; want %q, %v", tt.a, tt.b, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package ginit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Preset is a named combination of a project type, features and defaults,
+// such as a company HTTP service. Presets are selected with -preset and
+// defined in the settings file of users and organisations.
+type Preset struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type"`
+	Features    []string `json:"features,omitempty"`
+	// ModulePrefix is prepended to the project name when no module name is
+	// given, e.g. "github.com/myorg/"
+	ModulePrefix string `json:"module_prefix,omitempty"`
+}
+
+// Apply fills config from the preset. Fields already set in config win,
+// features are merged.
+func (p Preset) Apply(config Config) Config {
+	if config.ProjectType == "" {
+		config.ProjectType = p.Type
+	}
+	if config.ModuleName == "" && p.ModulePrefix != "" && config.ProjectName != "" {
+		config.ModuleName = p.ModulePrefix + config.ProjectName
+	}
+
+	features := slices.Clone(p.Features)
+	for _, f := range config.Features {
+		if !slices.Contains(features, f) {
+			features = append(features, f)
+		}
+	}
+	config.Features = features
+	return config
+}
+
+// Summary describes what the preset generates, e.g. "web + docker, ci"
+func (p Preset) Summary() string {
+	if len(p.Features) == 0 {
+		return p.Type
+	}
+	return p.Type + " + " + strings.Join(p.Features, ", ")
+}
+
+// PresetRegistry holds the presets available to the CLI and the wizard
+type PresetRegistry struct {
+	mu      sync.RWMutex
+	presets []Preset
+}
+
+// NewPresetRegistry returns an empty preset registry
+func NewPresetRegistry() *PresetRegistry {
+	return &PresetRegistry{}
+}
+
+// DefaultPresets returns a new registry with the built-in presets
+func DefaultPresets() *PresetRegistry {
+	r := NewPresetRegistry()
+	r.presets = append(r.presets, builtinPresets...)
+	return r
+}
+
+var builtinPresets = []Preset{
+	{
+		Name:        "http-service",
+		Description: "Containerised HTTP service with metrics and CI",
+		Type:        "web",
+		Features:    []string{"docker", "metrics", "ci", "lint"},
+	},
+	{
+		Name:        "postgres-service",
+		Description: "HTTP service backed by PostgreSQL with migrations",
+		Type:        "web",
+		Features:    []string{"docker", "migrations", "metrics", "ci", "lint"},
+	},
+	{
+		Name:        "cli-tool",
+		Description: "Command-line tool with CI and linting",
+		Type:        "cli",
+		Features:    []string{"ci", "lint"},
+	},
+}
+
+// Register adds a preset or replaces the one with the same name
+func (r *PresetRegistry) Register(p Preset) error {
+	if p.Name == "" {
+		return errors.New("preset name must not be empty")
+	}
+	if p.Type == "" {
+		return fmt.Errorf("preset %s: type must not be empty", p.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.presets {
+		if r.presets[i].Name == p.Name {
+			r.presets[i] = p
+			return nil
+		}
+	}
+	r.presets = append(r.presets, p)
+	return nil
+}
+
+// Lookup returns the named preset
+func (r *PresetRegistry) Lookup(name string) (Preset, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// List returns the registered presets in registration order
+func (r *PresetRegistry) List() []Preset {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.presets)
+}
+
+// Names returns the names of the registered presets in registration order
+func (r *PresetRegistry) Names() []string {
+	var names []string
+	for _, p := range r.List() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Get returns the named preset or a *ConfigError suggesting the closest
+// registered name
+func (r *PresetRegistry) Get(name string) (Preset, error) {
+	if p, ok := r.Lookup(name); ok {
+		return p, nil
+	}
+
+	available := strings.Join(r.Names(), ", ")
+	reason := "must be one of " + available
+	if s := closest(name, r.Names()); s != "" {
+		reason = fmt.Sprintf("did you mean %q? Available: %s", s, available)
+	}
+	return Preset{}, &ConfigError{Field: "preset", Value: name, Reason: reason}
+}
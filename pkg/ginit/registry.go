@@ -0,0 +1,170 @@
+package ginit
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Registry maps template names, such as "web/handlers.go", to their
+// text/template sources. A Plan refers to templates by name, so registering
+// a template under a built-in name replaces that file in generated projects.
+//
+// Templates named with PartialPrefix are partials: they are not rendered
+// into files but included by other templates, e.g. "partials/bootstrap" as
+// {{template "bootstrap" .}}. Partials of a template pack are registered
+// below the pack, as "packs/<name>/partials/bootstrap", and seen only by
+// projects of that pack or a pack extending it.
+type Registry struct {
+	mu        sync.RWMutex
+	templates map[string]string
+}
+
+// PartialPrefix starts the registry names of partials
+const PartialPrefix = "partials/"
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{templates: make(map[string]string)}
+}
+
+// DefaultRegistry returns a new registry filled with the built-in templates
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	for name, source := range builtinTemplates {
+		r.templates[name] = source
+	}
+	return r
+}
+
+var builtinTemplates = map[string]string{
+	"cli/main.go":        cliMainTemplate,
+	"cli/config.go":      cliConfigTemplate,
+	"cli/cli.go":         cliTemplate,
+	"cli/commands.go":    commandsTemplate,
+	"web/main.go":        webMainTemplate,
+	"web/config.go":      webConfigTemplate,
+	"web/app.go":         appTemplate,
+	"web/handlers.go":    handlersTemplate,
+	"library/version.go": versionTemplate,
+	"library/example.go": exampleTemplate,
+	"logger.go":          loggerTemplate,
+	"README.md":          readmeTemplate,
+	"gitignore":          gitignoreTemplate,
+
+	"grpc/main.go":        grpcMainTemplate,
+	"grpc/config.go":      grpcConfigTemplate,
+	"grpc/server.go":      grpcServerTemplate,
+	"grpc/server_test.go": grpcServerTestTemplate,
+	"grpc/service.proto":  grpcProtoTemplate,
+	"grpc/buf.yaml":       grpcBufTemplate,
+	"grpc/buf.gen.yaml":   grpcBufGenTemplate,
+	"grpc/generate.go":    grpcGenerateTemplate,
+
+	"worker/main.go":        workerMainTemplate,
+	"worker/config.go":      workerConfigTemplate,
+	"worker/worker.go":      workerTemplate,
+	"worker/memory.go":      workerMemoryTemplate,
+	"worker/worker_test.go": workerTestTemplate,
+	"worker/jobs.go":        workerJobsTemplate,
+
+	"partials/bootstrap.imports": bootstrapImportsPartial,
+	"partials/bootstrap":         bootstrapPartial,
+	"partials/main.setup":        mainSetupPartial,
+	"partials/config.fields":     configFieldsPartial,
+	"partials/config.defaults":   configDefaultsPartial,
+	"partials/config.load":       configLoadPartial,
+
+	"features/docker/Dockerfile":        dockerfileTemplate,
+	"features/docker/dockerignore":      dockerignoreTemplate,
+	"features/ci/ci.yml":                githubCITemplate,
+	"features/gitlab-ci/gitlab-ci.yml":  gitlabCITemplate,
+	"features/lint/golangci.yml":        golangciTemplate,
+	"features/license/LICENSE":          licenseTemplate,
+	"features/metrics/metrics.go":       metricsTemplate,
+	"features/database/database.go":     databaseTemplate,
+	"features/migrations/migrate.go":    migrateTemplate,
+	"features/migrations/0001_init.sql": initMigrationTemplate,
+	"features/redis/redis.go":           redisTemplate,
+	"features/auth/auth.go":             authTemplate,
+}
+
+// Register adds or replaces a template. The source is parsed right away so
+// that a broken template is reported here rather than during generation.
+func (r *Registry) Register(name, source string) error {
+	// Ошибки в партиале text/template называет по имени включения
+	parseName := name
+	if partial, ok := cutPartial(name); ok {
+		parseName = partial
+	}
+	if _, err := template.New(parseName).Funcs(FuncMap()).Parse(source); err != nil {
+		te := templateError(parseName, source, err)
+		te.Name = name
+		return te
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates[name] = source
+	return nil
+}
+
+// Lookup returns the source of the named template
+func (r *Registry) Lookup(name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	source, ok := r.templates[name]
+	return source, ok
+}
+
+// Partials returns the sources of the partials by the name templates
+// include them with, e.g. "bootstrap" for "partials/bootstrap". The
+// partials registered below the scopes, such as "packs/api/", are added
+// in order, each replacing a partial of the same name.
+func (r *Registry) Partials(scopes ...string) map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	partials := make(map[string]string)
+	for name, source := range r.templates {
+		if partial, ok := strings.CutPrefix(name, PartialPrefix); ok {
+			partials[partial] = source
+		}
+	}
+	for _, scope := range scopes {
+		for name, source := range r.templates {
+			if partial, ok := strings.CutPrefix(name, scope+PartialPrefix); ok {
+				partials[partial] = source
+			}
+		}
+	}
+	return partials
+}
+
+// cutPartial returns the name the partial with the registry name name is
+// included with, global or of a pack
+func cutPartial(name string) (string, bool) {
+	if partial, ok := strings.CutPrefix(name, PartialPrefix); ok {
+		return partial, true
+	}
+	rest, ok := strings.CutPrefix(name, "packs/")
+	if !ok {
+		return "", false
+	}
+	_, rest, _ = strings.Cut(rest, "/")
+	return strings.CutPrefix(rest, PartialPrefix)
+}
+
+// Names returns the names of all registered templates, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
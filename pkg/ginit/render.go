@@ -0,0 +1,274 @@
+package ginit
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// TemplateData is the data every template is executed with
+type TemplateData struct {
+	ProjectName    string
+	Module         string
+	Type           string
+	Features       []string
+	ReadmeSections []ReadmeSection
+	ConfigFields   []ConfigField
+	Snippets       []Snippet
+	// Vars holds the template variables of Config.Variables
+	Vars map[string]string
+}
+
+// File is a rendered project file
+type File struct {
+	// Path is relative to the project directory and uses forward slashes
+	Path    string
+	Content []byte
+}
+
+// Rendered is a Plan together with the contents of all its files
+type Rendered struct {
+	Plan  *Plan
+	Files []File
+}
+
+// pathError reports a problem with the project file at path as an error of
+// the template it is rendered from
+func (r *Rendered) pathError(path string, err error) *TemplateError {
+	te := &TemplateError{Path: path, Err: err}
+	for _, f := range r.Plan.Files {
+		if f.Path == path {
+			te.Name = f.Template
+		}
+	}
+	return te
+}
+
+// FuncMap returns the functions available to every template
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"lower":   strings.ToLower,
+		"upper":   strings.ToUpper,
+		"replace": strings.ReplaceAll,
+		"camel":   camelCase,
+		"pascal":  pascalCase,
+		"snake":   func(s string) string { return joinWords(s, "_") },
+		"kebab":   func(s string) string { return joinWords(s, "-") },
+	}
+}
+
+// Render executes the templates of plan in memory, formats the resulting
+// Go files with grouped imports and type-checks them. Nothing is written
+// to disk, so a failing template or code that does not compile never
+// leaves a half-generated project.
+func (g *Generator) Render(plan *Plan) (*Rendered, error) {
+	data := planData(plan)
+	scopes := g.partialScopes(plan.Config.ProjectType)
+	rendered := &Rendered{Plan: plan}
+	for _, f := range plan.Files {
+		content, err := g.renderTemplate(f.Template, data, scopes)
+		if err != nil {
+			if te, ok := err.(*TemplateError); ok {
+				te.Path = f.Path
+			}
+			return nil, err
+		}
+		if strings.HasSuffix(f.Path, ".go") {
+			content = formatGo(plan.Config.ModuleName, content)
+		}
+		rendered.Files = append(rendered.Files, File{Path: f.Path, Content: content})
+	}
+
+	if !stdlibAvailable() {
+		g.log.Warn("Go standard library not found, uses of it in the generated code are not type-checked")
+	}
+	if err := checkGo(plan.Config.ModuleName, rendered.Files); err != nil {
+		return nil, fmt.Errorf("generated code does not compile:\n%w", err)
+	}
+	return rendered, nil
+}
+
+// configData returns the template data known from config alone, without
+// the contributions of the plan
+func configData(config Config) TemplateData {
+	return TemplateData{
+		ProjectName: config.ProjectName,
+		Module:      config.ModuleName,
+		Type:        config.ProjectType,
+		Features:    config.Features,
+		Vars:        config.Variables,
+	}
+}
+
+// planData returns the data the templates of plan are executed with
+func planData(plan *Plan) TemplateData {
+	data := configData(plan.Config)
+	data.ReadmeSections = plan.ReadmeSections
+	data.ConfigFields = plan.ConfigFields
+	data.Snippets = plan.Snippets
+	return data
+}
+
+// partialScopes returns the scopes of the partials the templates of
+// projects of the named type include, see Registry.Partials
+func (g *Generator) partialScopes(projectType string) []string {
+	t, ok := g.types.Lookup(projectType)
+	if !ok {
+		return nil
+	}
+	return partialScopes(t)
+}
+
+// renderTemplate executes the named template with the global partials and
+// those of scopes
+func (g *Generator) renderTemplate(name string, data any, scopes []string) ([]byte, error) {
+	source, ok := g.registry.Lookup(name)
+	if !ok {
+		return nil, &TemplateError{Name: name, Err: ErrTemplateNotFound}
+	}
+
+	partials := g.registry.Partials(scopes...)
+	t, err := parseWithPartials(name, source, partials)
+	if err != nil {
+		return nil, g.scopedPartialError(name, source, partials, scopes, err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, g.scopedPartialError(name, source, partials, scopes, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scopedPartialError is partialError naming a partial of a pack by its
+// registry name
+func (g *Generator) scopedPartialError(name, source string, partials map[string]string, scopes []string, err error) *TemplateError {
+	te := partialError(name, source, partials, err)
+	if partial, ok := strings.CutPrefix(te.Name, PartialPrefix); ok && te.Name != name {
+		for _, scope := range slices.Backward(scopes) {
+			if _, ok := g.registry.Lookup(scope + PartialPrefix + partial); ok {
+				te.Name = scope + PartialPrefix + partial
+				break
+			}
+		}
+	}
+	return te
+}
+
+// parseWithPartials parses source as the template name together with the
+// partials it may include
+func parseWithPartials(name, source string, partials map[string]string) (*template.Template, error) {
+	t := template.New(name).Funcs(FuncMap())
+	for partial, partialSource := range partials {
+		if partial == name {
+			continue
+		}
+		if _, err := t.New(partial).Parse(partialSource); err != nil {
+			return nil, err
+		}
+	}
+	return t.Parse(source)
+}
+
+// partialError is templateError for a template rendered with partials: an
+// error inside a partial is located in the partial's source
+func partialError(name, source string, partials map[string]string, err error) *TemplateError {
+	te := templateError(name, source, err)
+	if te.Line > 0 {
+		return te
+	}
+	for partial, partialSource := range partials {
+		if pte := templateError(partial, partialSource, err); pte.Line > 0 {
+			pte.Name = PartialPrefix + partial
+			return pte
+		}
+	}
+	return te
+}
+
+// templateErrorPos matches the position text/template puts in front of
+// parse and execution errors: "template: name:line[:column]: message"
+var templateErrorPos = regexp.MustCompile(`(?s)^template: (.*?):(\d+)(?::(\d+))?: (.*)$`)
+
+// templateError wraps an error of parsing or executing the template name
+// with its position and the offending line of source
+func templateError(name, source string, err error) *TemplateError {
+	te := &TemplateError{Name: name, Err: err}
+	m := templateErrorPos.FindStringSubmatch(err.Error())
+	// Ошибка во вложенном шаблоне относится к другому исходнику
+	if m == nil || m[1] != name {
+		return te
+	}
+
+	te.Line, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		// text/template считает столбец с нуля
+		column, _ := strconv.Atoi(m[3])
+		te.Column = column + 1
+	}
+	if lines := strings.Split(source, "\n"); te.Line <= len(lines) {
+		te.Snippet = strings.TrimRight(lines[te.Line-1], "\r")
+	}
+	return te
+}
+
+// words splits a project name like "my-app_v2" or "myApp" into words
+func words(s string) []string {
+	var result []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	return result
+}
+
+func joinWords(s, sep string) string {
+	w := words(s)
+	for i := range w {
+		w[i] = strings.ToLower(w[i])
+	}
+	return strings.Join(w, sep)
+}
+
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		r := []rune(strings.ToLower(w))
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+func camelCase(s string) string {
+	r := []rune(pascalCase(s))
+	if len(r) == 0 {
+		return ""
+	}
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -0,0 +1,164 @@
+package ginit
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// VCS statuses reported in Result.VCS.Status
+const (
+	VCSInitialized = "initialized"
+	VCSDisabled    = "disabled"
+	VCSSkipped     = "skipped"
+)
+
+// Result describes what Apply produced. It is designed to be encoded
+// as JSON for tools that wrap ginit.
+type Result struct {
+	Path         string       `json:"path"`
+	Project      string       `json:"project"`
+	Module       string       `json:"module"`
+	Type         string       `json:"type"`
+	Features     []string     `json:"features"`
+	Files        []FileResult `json:"files"`
+	Dependencies []Dependency `json:"dependencies"`
+	VCS          VCSResult    `json:"vcs"`
+	Warnings     []string     `json:"warnings"`
+	NextSteps    []string     `json:"next_steps"`
+	Timings      Timings      `json:"timings"`
+}
+
+type FileResult struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type Dependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+type VCSResult struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type Timings struct {
+	TotalMS float64      `json:"total_ms"`
+	Steps   []StepTiming `json:"steps"`
+}
+
+type StepTiming struct {
+	Step       string  `json:"step"`
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+func newResult(config Config) *Result {
+	return &Result{
+		Path:         config.Directory,
+		Project:      config.ProjectName,
+		Module:       config.ModuleName,
+		Type:         config.ProjectType,
+		Features:     []string{},
+		Files:        []FileResult{},
+		Dependencies: []Dependency{},
+		VCS:          VCSResult{Status: VCSDisabled},
+		Warnings:     []string{},
+		NextSteps:    []string{},
+		Timings:      Timings{Steps: []StepTiming{}},
+	}
+}
+
+func (r *Result) addStep(title string, err error, d time.Duration) {
+	status := "ok"
+	if err != nil {
+		status = "failed"
+	}
+	r.Timings.Steps = append(r.Timings.Steps, StepTiming{
+		Step:       title,
+		Status:     status,
+		DurationMS: milliseconds(d),
+	})
+}
+
+// collectFiles fills Files with the final size and checksum of every file
+// written during generation. Files are hashed at the end because go.mod is
+// rewritten by `go get` after it was first created.
+func (r *Result) collectFiles(dir string, paths []string) error {
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil {
+			return err
+		}
+		r.Files = append(r.Files, FileResult{
+			Path:   path,
+			Size:   int64(len(data)),
+			SHA256: checksum(data),
+		})
+	}
+	return nil
+}
+
+// requiredVersions returns the versions of the given modules from the
+// require directives of go.mod
+func requiredVersions(goModPath string, modules []string) ([]Dependency, error) {
+	file, err := os.Open(goModPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	versions := make(map[string]string)
+	inBlock := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		switch {
+		case line == "require (":
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inBlock:
+			continue
+		}
+
+		if fields := strings.Fields(line); len(fields) == 2 {
+			versions[fields[0]] = fields[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	for _, module := range modules {
+		if version, ok := versions[module]; ok {
+			deps = append(deps, Dependency{Path: module, Version: version})
+		}
+	}
+	return deps, nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,108 @@
+package ginit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Settings is the ginit settings file of a user or an organisation
+type Settings struct {
+	// Include lists settings files whose presets are loaded first, e.g. a
+	// file shared by the organisation. Relative paths are resolved against
+	// the directory of the including file.
+	Include []string `json:"include,omitempty"`
+	Presets []Preset `json:"presets,omitempty"`
+	// Policy is enforced when generating projects. Policies of included
+	// files are merged, see Policy.
+	Policy *Policy `json:"policy,omitempty"`
+	// TrustedKeys are the keys installed template packs must be signed
+	// with, see TemplateIndex.Trust. Keys of included files are added.
+	TrustedKeys []TrustedKey `json:"trusted_keys,omitempty"`
+}
+
+// SettingsPath returns the path of the user settings file:
+// $GINIT_CONFIG if set, otherwise ginit/config.json in os.UserConfigDir
+func SettingsPath() (string, error) {
+	if path := os.Getenv("GINIT_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ginit", "config.json"), nil
+}
+
+// LoadSettings reads the settings file at path together with the files it
+// includes. A missing file yields empty settings. The presets of included
+// files come first, so the including file can override them by name;
+// policies are merged and trusted keys added up.
+func LoadSettings(path string) (*Settings, error) {
+	return loadSettings(path, nil)
+}
+
+func loadSettings(path string, seen []string) (*Settings, error) {
+	if slices.Contains(seen, path) {
+		return nil, fmt.Errorf("settings %s: include cycle", path)
+	}
+	seen = append(seen, path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && len(seen) == 1 {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid settings %s: %w", path, err)
+	}
+	for _, k := range s.TrustedKeys {
+		switch {
+		case k.Name == "" || k.Key == "":
+			return nil, fmt.Errorf("invalid settings %s: trusted keys need a name and a key", path)
+		case k.Type != KeySSH && k.Type != KeyMinisign:
+			return nil, fmt.Errorf("invalid settings %s: trusted key %s has type %q, expected %s or %s", path, k.Name, k.Type, KeySSH, KeyMinisign)
+		}
+	}
+
+	merged := &Settings{Include: s.Include}
+	for _, inc := range s.Include {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(path), inc)
+		}
+		included, err := loadSettings(inc, seen)
+		if err != nil {
+			return nil, err
+		}
+		merged.Presets = append(merged.Presets, included.Presets...)
+		if merged.Policy, err = merged.Policy.merge(included.Policy); err != nil {
+			return nil, fmt.Errorf("settings %s: %w", path, err)
+		}
+		merged.TrustedKeys = append(merged.TrustedKeys, included.TrustedKeys...)
+	}
+	merged.Presets = append(merged.Presets, s.Presets...)
+	if merged.Policy, err = merged.Policy.merge(s.Policy); err != nil {
+		return nil, fmt.Errorf("settings %s: %w", path, err)
+	}
+	merged.TrustedKeys = append(merged.TrustedKeys, s.TrustedKeys...)
+	return merged, nil
+}
+
+// PresetRegistry returns the built-in presets extended with the presets
+// of the settings
+func (s *Settings) PresetRegistry() (*PresetRegistry, error) {
+	r := DefaultPresets()
+	for _, p := range s.Presets {
+		if err := r.Register(p); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,469 @@
+package ginit
+
+// Встроенные шаблоны файлов проекта. Все они рендерятся через text/template
+// с данными TemplateData.
+
+const cliMainTemplate = `package main
+
+import (
+{{template "bootstrap.imports" .}}
+
+	"{{.Module}}/internal/cli"
+)
+
+func main() {
+{{template "bootstrap" .}}
+	log.InfoContext(ctx, "Starting {{.ProjectName}} CLI...")
+{{- template "main.setup" .}}
+	
+	// Запуск CLI приложения
+	if err := cli.Run(ctx, cfg, log); err != nil {
+		log.ErrorContext(ctx, "CLI execution failed", "error", err)
+		os.Exit(1)
+	}
+	
+	log.InfoContext(ctx, "CLI application stopped")
+}
+`
+
+const webMainTemplate = `package main
+
+import (
+{{template "bootstrap.imports" .}}
+	"os/signal"
+	"syscall"
+
+	"{{.Module}}/internal/app"
+)
+
+func main() {
+{{template "bootstrap" .}}
+	log.InfoContext(ctx, "Starting {{.ProjectName}} web server...")
+{{- template "main.setup" .}}
+	
+	// Создание и запуск приложения
+	application := app.New(cfg, log)
+	
+	// Graceful shutdown
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	
+	go func() {
+		if err := application.Run(ctx); err != nil {
+			log.ErrorContext(ctx, "Application failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+	
+	<-stop
+	log.InfoContext(ctx, "Shutting down server...")
+	
+	if err := application.Shutdown(ctx); err != nil {
+		log.ErrorContext(ctx, "Graceful shutdown failed", "error", err)
+	}
+	
+	log.InfoContext(ctx, "Server stopped")
+}
+`
+
+// Партиалы, общие для типов проектов. Шаблон включает их по имени без
+// префикса partials/, например {{template "bootstrap" .}}.
+
+// bootstrapImportsPartial are the imports the bootstrap partial needs,
+// for an import block
+const bootstrapImportsPartial = `	"context"
+	"fmt"
+	"os"
+
+	"{{.Module}}/internal/config"
+	"{{.Module}}/pkg/logger"
+{{- range .SnippetImports "main.setup"}}
+	"{{.}}"
+{{- end}}`
+
+// bootstrapPartial loads the config and creates the logger and the
+// context at the start of main
+const bootstrapPartial = `	// Загрузка конфигурации
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Инициализация логгера
+	log := logger.New(cfg.LogLevel)
+
+	ctx := context.Background()`
+
+// mainSetupPartial is the code features add to main after the bootstrap
+const mainSetupPartial = `
+{{- range .SnippetCode "main.setup"}}
+
+{{.}}
+{{- end}}`
+
+// configFieldsPartial are the Config struct fields added by features
+const configFieldsPartial = `
+{{- range .ConfigFields}}
+	{{.Name}} {{.Type}}{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}`
+
+// configDefaultsPartial are the defaults of the fields added by features
+const configDefaultsPartial = `
+{{- range .ConfigFields}}
+		{{.Name}}: {{.Default}},
+{{- end}}`
+
+// configLoadPartial is the Load singleton around the defaults function
+// of a config package
+const configLoadPartial = `var (
+	instance *Config
+	once     sync.Once
+)
+
+// Load возвращает конфигурацию, загружая ее при первом вызове
+func Load() (*Config, error) {
+	var err error
+	once.Do(func() {
+		instance = defaults()
+		// Здесь будет загрузка из env/config file
+	})
+	return instance, err
+}`
+
+const cliConfigTemplate = `package config
+
+import (
+	"sync"
+)
+
+type Config struct {
+	LogLevel   string
+	Verbose    bool
+	ConfigPath string
+{{- template "config.fields" .}}
+}
+
+func defaults() *Config {
+	return &Config{
+		LogLevel:   "info",
+		Verbose:    false,
+		ConfigPath: "config.yaml",
+{{- template "config.defaults" .}}
+	}
+}
+
+{{template "config.load" .}}
+`
+
+const webConfigTemplate = `package config
+
+import (
+	"sync"
+)
+
+type Config struct {
+	HTTPPort  string
+	LogLevel  string
+{{- template "config.fields" .}}
+}
+
+func defaults() *Config {
+	return &Config{
+		HTTPPort: ":8080",
+		LogLevel: "info",
+{{- template "config.defaults" .}}
+	}
+}
+
+{{template "config.load" .}}
+`
+
+const loggerTemplate = `package logger
+
+import (
+	"log/slog"
+	"os"
+)
+
+// New создает новый логгер slog с указанным уровнем
+func New(level string) *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, options(level)))
+}
+
+// NewJSON создает логгер с JSON форматом
+func NewJSON(level string) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stdout, options(level)))
+}
+
+// options переводит уровень из конфигурации в настройки обработчика
+func options(level string) *slog.HandlerOptions {
+	var logLevel slog.Level
+
+	switch level {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	default:
+		logLevel = slog.LevelInfo
+	}
+
+	return &slog.HandlerOptions{Level: logLevel}
+}
+`
+
+const cliTemplate = `package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"log/slog"
+
+	"{{.Module}}/internal/config"
+	"{{.Module}}/internal/commands"
+)
+
+func Run(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
+	var (
+		verbose bool
+		version bool
+	)
+
+	flag.BoolVar(&verbose, "verbose", cfg.Verbose, "Enable verbose output")
+	flag.BoolVar(&version, "version", false, "Show version information")
+	flag.Parse()
+
+	if version {
+		fmt.Println("{{.ProjectName}} v1.0.0")
+		return nil
+	}
+
+	if verbose {
+		log.InfoContext(ctx, "Verbose mode enabled")
+	}
+
+	// Execute command
+	if flag.NArg() > 0 {
+		cmd := flag.Arg(0)
+		return commands.Execute(ctx, cmd, flag.Args()[1:], cfg, log)
+	}
+
+	// Default command
+	return commands.Default(ctx, cfg, log)
+}
+`
+
+const commandsTemplate = `package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"{{.Module}}/internal/config"
+)
+
+func Execute(ctx context.Context, cmd string, args []string, cfg *config.Config, log *slog.Logger) error {
+	switch cmd {
+	case "help":
+		return Help(ctx, args, cfg, log)
+	default:
+		return fmt.Errorf("unknown command: %s", cmd)
+	}
+}
+
+func Default(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
+	log.InfoContext(ctx, "Running default command")
+	fmt.Println("Welcome to {{.ProjectName}}!")
+	fmt.Println("Use '{{.ProjectName}} help' for available commands.")
+	return nil
+}
+
+func Help(ctx context.Context, args []string, cfg *config.Config, log *slog.Logger) error {
+	fmt.Println("Available commands:")
+	fmt.Println("  help    - Show this help message")
+	fmt.Println("  version - Show version information")
+	return nil
+}
+`
+
+const appTemplate = `package app
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"{{.Module}}/internal/config"
+	"{{.Module}}/internal/handlers"
+{{- range .SnippetImports "app.handler"}}
+	"{{.}}"
+{{- end}}
+)
+
+type App struct {
+	config *config.Config
+	log    *slog.Logger
+	server *http.Server
+}
+
+func New(cfg *config.Config, log *slog.Logger) *App {
+	return &App{
+		config: cfg,
+		log:    log,
+	}
+}
+
+func (a *App) Run(ctx context.Context) error {
+	a.log.InfoContext(ctx, "Starting HTTP server", "port", a.config.HTTPPort)
+	
+	var handler http.Handler = handlers.New(a.config, a.log)
+{{- range .SnippetCode "app.handler"}}
+{{.}}
+{{- end}}
+	
+	a.server = &http.Server{
+		Addr:    a.config.HTTPPort,
+		Handler: handler,
+	}
+	
+	return a.server.ListenAndServe()
+}
+
+func (a *App) Shutdown(ctx context.Context) error {
+	if a.server != nil {
+		return a.server.Shutdown(ctx)
+	}
+	return nil
+}
+`
+
+const handlersTemplate = `package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"{{.Module}}/internal/config"
+)
+
+type Handler struct {
+	config *config.Config
+	log    *slog.Logger
+}
+
+func New(cfg *config.Config, log *slog.Logger) *Handler {
+	return &Handler{
+		config: cfg,
+		log:    log,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.log.InfoContext(r.Context(), "HTTP request", "method", r.Method, "path", r.URL.Path)
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(` + "`{\"message\": \"Hello from {{.ProjectName}}\"}`" + `))
+}
+`
+
+const versionTemplate = `package version
+
+// Version of the library
+const Version = "v1.0.0"
+`
+
+const exampleTemplate = `package main
+
+import (
+	"fmt"
+
+	"{{.Module}}/pkg/version"
+)
+
+func main() {
+	fmt.Printf("Using {{.ProjectName}} version: %s\n", version.Version)
+	fmt.Println("This is an example of how to use the library.")
+}
+`
+
+const gitignoreTemplate = `# Binaries
+bin/
+dist/
+
+# Dependencies
+vendor/
+
+# Environment files
+.env
+.env.local
+
+# IDE
+.vscode/
+.idea/
+
+# Build artifacts
+*.exe
+*.dll
+*.so
+*.dylib
+
+# Test output
+coverage.txt
+profile.out
+
+# Go workspace
+go.work
+go.work.sum
+`
+
+const readmeTemplate = `# {{.ProjectName}}
+
+A Go project generated with ginit.
+
+## Features
+
+- Modern Go project structure
+- Configuration management
+- Structured logging with slog
+- Ready for production
+
+## Getting Started
+
+### Prerequisites
+- Go 1.21+ (for slog support)
+
+### Installation
+
+1. Build the project:
+` + "```bash" + `
+go build -o bin/{{.ProjectName}} ./cmd/{{.ProjectName}}
+` + "```" + `
+
+2. Run:
+` + "```bash" + `
+./bin/{{.ProjectName}}
+` + "```" + `
+
+### Development
+
+Run with hot reload (if you have air/gin installed):
+` + "```bash" + `
+air
+# or
+gin -i run cmd/{{.ProjectName}}/main.go
+` + "```" + `
+
+{{- range .ReadmeSections}}
+
+## {{.Title}}
+
+{{.Body}}
+{{- end}}
+`
@@ -0,0 +1,72 @@
+package ginit
+
+// cliType is a command-line application
+type cliType struct{}
+
+func (cliType) Info() TypeInfo {
+	return TypeInfo{
+		Name:        "cli",
+		Title:       "CLI Application",
+		Description: "Command-line tool with commands, env config and a logger",
+	}
+}
+
+func (cliType) Directories(config Config) []string {
+	return []string{
+		"cmd/" + config.ProjectName,
+		"internal/config",
+		"internal/cli",
+		"internal/commands",
+		"pkg/logger",
+		"pkg/utils",
+		"pkg/version",
+	}
+}
+
+func (cliType) Files(config Config) []PlannedFile {
+	return []PlannedFile{
+		{"cmd/" + config.ProjectName + "/main.go", "cli/main.go"},
+		{"internal/config/config.go", "cli/config.go"},
+		{"pkg/logger/logger.go", "logger.go"},
+		{"internal/cli/cli.go", "cli/cli.go"},
+		{"internal/commands/commands.go", "cli/commands.go"},
+	}
+}
+
+func (cliType) Dependencies(config Config) []string {
+	return []string{
+		"github.com/caarlos0/env/v11",
+	}
+}
+
+func (cliType) NextSteps(config Config) []string {
+	return binarySteps(config)
+}
+
+func (cliType) ReadmeSections(config Config) []ReadmeSection {
+	name := config.ProjectName
+	return []ReadmeSection{
+		{
+			Title: "Project Structure",
+			Body: fenced("", name+`/
+├── cmd/`+name+`/main.go
+├── internal/
+│   ├── cli/        # Command-line parsing
+│   ├── commands/   # Command implementations
+│   └── config/     # Configuration management
+├── pkg/
+│   ├── logger/     # slog-based logging
+│   ├── utils/      # Shared utilities
+│   └── version/    # Version information`),
+		},
+		{
+			Title: "Configuration",
+			Body: `The defaults are set in ` + "`internal/config/config.go`" + `:
+
+- ` + "`LogLevel`" + `: Log level (debug, info, warn, error) (default: info)
+- ` + "`Verbose`" + `: Verbose output (default: false)
+- ` + "`ConfigPath`" + `: Path to the config file (default: config.yaml)`,
+		},
+		loggingSection(),
+	}
+}
@@ -0,0 +1,90 @@
+package ginit
+
+// grpcType is a gRPC service
+type grpcType struct{}
+
+func (grpcType) Info() TypeInfo {
+	return TypeInfo{
+		Name:        "grpc",
+		Title:       "gRPC Service",
+		Description: "gRPC server with a protobuf API, health and reflection services and graceful shutdown",
+	}
+}
+
+// protoDir is the directory of the protobuf package of the service
+func protoDir(config Config) string {
+	return "api/proto/" + joinWords(config.ProjectName, "_") + "/v1"
+}
+
+func (grpcType) Directories(config Config) []string {
+	return []string{
+		"cmd/" + config.ProjectName,
+		"internal/config",
+		"internal/server",
+		"pkg/logger",
+		protoDir(config),
+	}
+}
+
+func (grpcType) Files(config Config) []PlannedFile {
+	return []PlannedFile{
+		{"cmd/" + config.ProjectName + "/main.go", "grpc/main.go"},
+		{"internal/config/config.go", "grpc/config.go"},
+		{"pkg/logger/logger.go", "logger.go"},
+		{"internal/server/server.go", "grpc/server.go"},
+		{"internal/server/server_test.go", "grpc/server_test.go"},
+		{protoDir(config) + "/" + joinWords(config.ProjectName, "_") + ".proto", "grpc/service.proto"},
+		{"api/buf.yaml", "grpc/buf.yaml"},
+		{"api/buf.gen.yaml", "grpc/buf.gen.yaml"},
+		{"api/generate.go", "grpc/generate.go"},
+	}
+}
+
+func (grpcType) Dependencies(config Config) []string {
+	return []string{
+		"google.golang.org/grpc",
+	}
+}
+
+func (grpcType) NextSteps(config Config) []string {
+	return append(binarySteps(config), "grpcurl -plaintext localhost:50051 list")
+}
+
+func (grpcType) ReadmeSections(config Config) []ReadmeSection {
+	name := config.ProjectName
+	proto := joinWords(name, "_")
+	return []ReadmeSection{
+		{
+			Title: "Project Structure",
+			Body: fenced("", name+`/
+├── cmd/`+name+`/main.go
+├── api/
+│   ├── buf.yaml         # buf module and lint rules
+│   ├── buf.gen.yaml     # Code generation plugins
+│   ├── generate.go      # go:generate directive running buf
+│   └── proto/`+proto+`/v1/  # Protobuf definitions
+├── internal/
+│   ├── config/          # Configuration management
+│   └── server/          # gRPC server, health, reflection and graceful shutdown
+└── pkg/
+    └── logger/          # slog-based logging`),
+		},
+		{
+			Title: "API",
+			Body: "The service is defined in `" + protoDir(config) + "/" + proto + ".proto`. " +
+				"Generate the Go code into `api/gen` with [buf](https://buf.build/docs/installation):\n\n" +
+				fenced("bash", "go generate ./...") + "\n\n" +
+				"Then implement the generated server interface and register it in `server.New` (`internal/server/server.go`). " +
+				"The health and reflection services are registered already, so the running server can be explored with grpcurl:\n\n" +
+				fenced("bash", "grpcurl -plaintext localhost:50051 list"),
+		},
+		{
+			Title: "Configuration",
+			Body: `The application uses environment variables for configuration:
+
+- ` + "`GRPC_ADDR`" + `: Address of the gRPC server (default: :50051)
+- ` + "`LOG_LEVEL`" + `: Log level (debug, info, warn, error) (default: info)`,
+		},
+		loggingSection(),
+	}
+}
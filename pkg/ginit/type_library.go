@@ -0,0 +1,64 @@
+package ginit
+
+// libraryType is a reusable package without a binary of its own
+type libraryType struct{}
+
+func (libraryType) Info() TypeInfo {
+	return TypeInfo{
+		Name:        "library",
+		Title:       "Library",
+		Description: "Reusable package with version info and an example",
+	}
+}
+
+func (libraryType) Directories(config Config) []string {
+	return []string{
+		"internal/",
+		"pkg/",
+		"pkg/version",
+		"examples/",
+		"docs/",
+	}
+}
+
+func (libraryType) Files(config Config) []PlannedFile {
+	// Library projects have neither a binary nor internal/config and
+	// pkg/logger
+	return []PlannedFile{
+		{"pkg/version/version.go", "library/version.go"},
+		{"examples/example.go", "library/example.go"},
+	}
+}
+
+func (libraryType) Dependencies(config Config) []string {
+	// Library projects typically don't need external dependencies
+	return nil
+}
+
+func (libraryType) NextSteps(config Config) []string {
+	return []string{
+		"go mod tidy",
+		"go test ./...",
+		"go run ./examples",
+	}
+}
+
+func (libraryType) ReadmeSections(config Config) []ReadmeSection {
+	return []ReadmeSection{
+		{
+			Title: "Project Structure",
+			Body: fenced("", config.ProjectName+`/
+├── docs/           # Documentation
+├── examples/       # Usage examples
+├── internal/       # Private packages
+└── pkg/
+    └── version/    # Version information`),
+		},
+		{
+			Title: "Usage",
+			Body: fenced("go", `import "`+config.ModuleName+`/pkg/version"
+
+fmt.Println(version.Version)`),
+		},
+	}
+}
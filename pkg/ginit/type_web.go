@@ -0,0 +1,86 @@
+package ginit
+
+// webType is an HTTP service
+type webType struct{}
+
+func (webType) Info() TypeInfo {
+	return TypeInfo{
+		Name:        "web",
+		Title:       "Web Application",
+		Description: "HTTP service with handlers, env config, a logger and graceful shutdown",
+	}
+}
+
+func (webType) Directories(config Config) []string {
+	return []string{
+		"cmd/" + config.ProjectName,
+		"internal/config",
+		"internal/app",
+		"internal/handlers",
+		"internal/middleware",
+		"internal/models",
+		"internal/repository",
+		"internal/service",
+		"pkg/logger",
+		"pkg/utils",
+		"pkg/database",
+		"api/",
+		"web/static/",
+		"web/templates/",
+	}
+}
+
+func (webType) Files(config Config) []PlannedFile {
+	return []PlannedFile{
+		{"cmd/" + config.ProjectName + "/main.go", "web/main.go"},
+		{"internal/config/config.go", "web/config.go"},
+		{"pkg/logger/logger.go", "logger.go"},
+		{"internal/app/app.go", "web/app.go"},
+		{"internal/handlers/handlers.go", "web/handlers.go"},
+	}
+}
+
+func (webType) Dependencies(config Config) []string {
+	return []string{
+		"github.com/caarlos0/env/v11",
+	}
+}
+
+func (webType) NextSteps(config Config) []string {
+	return binarySteps(config)
+}
+
+func (webType) ReadmeSections(config Config) []ReadmeSection {
+	name := config.ProjectName
+	return []ReadmeSection{
+		{
+			Title: "Project Structure",
+			Body: fenced("", name+`/
+├── cmd/`+name+`/main.go
+├── api/            # API definitions
+├── internal/
+│   ├── app/        # HTTP server and graceful shutdown
+│   ├── config/     # Configuration management
+│   ├── handlers/   # HTTP handlers
+│   ├── middleware/ # HTTP middleware
+│   ├── models/     # Domain models
+│   ├── repository/ # Data access
+│   └── service/    # Business logic
+├── pkg/
+│   ├── database/   # Database helpers
+│   ├── logger/     # slog-based logging
+│   └── utils/      # Shared utilities
+└── web/
+    ├── static/     # Static assets
+    └── templates/  # HTML templates`),
+		},
+		{
+			Title: "Configuration",
+			Body: `The application uses environment variables for configuration:
+
+- ` + "`HTTP_PORT`" + `: Port for HTTP server (default: :8080)
+- ` + "`LOG_LEVEL`" + `: Log level (debug, info, warn, error) (default: info)`,
+		},
+		loggingSection(),
+	}
+}
@@ -0,0 +1,78 @@
+package ginit
+
+// workerType is a background worker consuming jobs from a queue
+type workerType struct{}
+
+func (workerType) Info() TypeInfo {
+	return TypeInfo{
+		Name:        "worker",
+		Title:       "Background Worker",
+		Description: "Queue consumer with a worker pool, retries with backoff, dead letters and graceful drain",
+	}
+}
+
+func (workerType) Directories(config Config) []string {
+	return []string{
+		"cmd/" + config.ProjectName,
+		"internal/config",
+		"internal/worker",
+		"internal/jobs",
+		"pkg/logger",
+	}
+}
+
+func (workerType) Files(config Config) []PlannedFile {
+	return []PlannedFile{
+		{"cmd/" + config.ProjectName + "/main.go", "worker/main.go"},
+		{"internal/config/config.go", "worker/config.go"},
+		{"pkg/logger/logger.go", "logger.go"},
+		{"internal/worker/worker.go", "worker/worker.go"},
+		{"internal/worker/memory.go", "worker/memory.go"},
+		{"internal/worker/worker_test.go", "worker/worker_test.go"},
+		{"internal/jobs/jobs.go", "worker/jobs.go"},
+	}
+}
+
+func (workerType) Dependencies(config Config) []string {
+	return nil
+}
+
+func (workerType) NextSteps(config Config) []string {
+	return binarySteps(config)
+}
+
+func (workerType) ReadmeSections(config Config) []ReadmeSection {
+	name := config.ProjectName
+	return []ReadmeSection{
+		{
+			Title: "Project Structure",
+			Body: fenced("", name+`/
+├── cmd/`+name+`/main.go
+├── internal/
+│   ├── config/     # Configuration management
+│   ├── jobs/       # Job handlers
+│   └── worker/     # Worker pool, job sources, retries and dead letters
+└── pkg/
+    └── logger/     # slog-based logging`),
+		},
+		{
+			Title: "Jobs",
+			Body: "Jobs come from a `worker.Source`: `Next` blocks until a job is available, `Ack` confirms a processed job and `DeadLetter` sets aside a job that failed every attempt. " +
+				"`worker.MemorySource` keeps jobs in memory for tests and local runs; replace it in `main.go` with a source for your queue.\n\n" +
+				"A failed job is retried with exponential backoff up to `MaxAttempts` times, errors wrapped with `worker.Permanent` are not retried. " +
+				"On SIGINT or SIGTERM the worker stops taking new jobs and waits up to `DrainTimeout` for running ones; jobs still running after that are cancelled and left unacknowledged for the queue to redeliver.",
+		},
+		{
+			Title: "Configuration",
+			Body: `The application uses environment variables for configuration:
+
+- ` + "`WORKERS`" + `: Number of jobs processed concurrently (default: 4)
+- ` + "`MAX_ATTEMPTS`" + `: Attempts per job before it is dead-lettered (default: 5)
+- ` + "`RETRY_BACKOFF`" + `: Delay before the first retry, doubled for each further one (default: 1s)
+- ` + "`MAX_BACKOFF`" + `: Upper limit of the retry delay (default: 1m)
+- ` + "`DRAIN_TIMEOUT`" + `: Time running jobs get to finish on shutdown (default: 30s)
+- ` + "`LOG_LEVEL`" + `: Log level (debug, info, warn, error) (default: info)`,
+		},
+		loggingSection(),
+	}
+}
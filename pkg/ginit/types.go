@@ -0,0 +1,251 @@
+package ginit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ProjectType defines the layout of one kind of project. Implement it and
+// register it with TypeRegistry.Register to add a project type; the files
+// it returns refer to templates that must be in the Generator's Registry.
+type ProjectType interface {
+	Info() TypeInfo
+	// Directories are created even if no file is written into them
+	Directories(config Config) []string
+	// Files are the type-specific files; README.md and .gitignore are
+	// added for every type
+	Files(config Config) []PlannedFile
+	// Dependencies are module paths passed to go get
+	Dependencies(config Config) []string
+	// NextSteps are shell commands to run in the project directory after
+	// generation, e.g. how to build and start the project
+	NextSteps(config Config) []string
+	// ReadmeSections are appended to the generated README.md
+	ReadmeSections(config Config) []ReadmeSection
+}
+
+// VariableType is implemented by project types whose templates read
+// variables. Plan fills in defaults and rejects unknown variables and
+// missing required ones.
+type VariableType interface {
+	ProjectType
+	Variables() []TemplateVariable
+}
+
+// ExtendingType is implemented by project types built on another type,
+// such as template packs that extend a built-in type. Features available
+// for the base type are available for the extending type as well.
+type ExtendingType interface {
+	ProjectType
+	// Base returns the extended type, or nil
+	Base() ProjectType
+}
+
+// TemplateVariable is a value given by the user, e.g. with -var, that
+// templates read as .Vars.<Name>
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// TypeInfo describes a project type
+type TypeInfo struct {
+	// Name is the value of Config.ProjectType, e.g. "web"
+	Name string
+	// Title is the human-readable name shown in the wizard
+	Title       string
+	Description string
+}
+
+// ReadmeSection is a level-two section of the generated README.md; Body
+// is Markdown
+type ReadmeSection struct {
+	Title string
+	Body  string
+}
+
+// TypeRegistry holds the project types a Generator accepts. Plan rejects
+// any Config.ProjectType that is not registered.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types []ProjectType
+}
+
+// NewTypeRegistry returns an empty type registry
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{}
+}
+
+// DefaultTypes returns a new registry with the built-in project types
+func DefaultTypes() *TypeRegistry {
+	r := NewTypeRegistry()
+	r.types = append(r.types, cliType{}, webType{}, grpcType{}, workerType{}, libraryType{})
+	return r
+}
+
+// Register adds a project type or replaces the one with the same name
+func (r *TypeRegistry) Register(t ProjectType) error {
+	name := t.Info().Name
+	if name == "" {
+		return errors.New("project type name must not be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.types {
+		if r.types[i].Info().Name == name {
+			r.types[i] = t
+			return nil
+		}
+	}
+	r.types = append(r.types, t)
+	return nil
+}
+
+// Lookup returns the named project type
+func (r *TypeRegistry) Lookup(name string) (ProjectType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, t := range r.types {
+		if t.Info().Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Root returns the name of the type the named type is ultimately built on,
+// see ExtendingType, or name itself
+func (r *TypeRegistry) Root(name string) string {
+	t, ok := r.Lookup(name)
+	if !ok {
+		return name
+	}
+	for {
+		e, ok := t.(ExtendingType)
+		if !ok || e.Base() == nil {
+			return t.Info().Name
+		}
+		t = e.Base()
+	}
+}
+
+// List describes the registered project types in registration order
+func (r *TypeRegistry) List() []TypeInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]TypeInfo, 0, len(r.types))
+	for _, t := range r.types {
+		info := t.Info()
+		if info.Title == "" {
+			info.Title = info.Name
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// Names returns the names of the registered project types in registration
+// order
+func (r *TypeRegistry) Names() []string {
+	var names []string
+	for _, t := range r.List() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// Check returns a *ConfigError if name is not a registered project type.
+// The error suggests the closest registered name when there is one.
+func (r *TypeRegistry) Check(name string) error {
+	if _, ok := r.Lookup(name); ok {
+		return nil
+	}
+
+	available := strings.Join(r.Names(), ", ")
+	reason := "must be one of " + available
+	if s := r.Suggest(name); s != "" {
+		reason = fmt.Sprintf("did you mean %q? Available: %s", s, available)
+	}
+	return &ConfigError{Field: "project type", Value: name, Reason: reason}
+}
+
+// Suggest returns the registered name closest to name, or "" if none is
+// close enough to be a likely typo
+func (r *TypeRegistry) Suggest(name string) string {
+	return closest(name, r.Names())
+}
+
+// closest returns the candidate closest to name, or "" if none is close
+// enough to be a likely typo
+func closest(name string, candidates []string) string {
+	name = strings.ToLower(name)
+
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, name) && name != "" {
+			return candidate
+		}
+		d := levenshtein(name, candidate)
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	// Не предлагаем то, что отличается больше чем на треть
+	if bestDistance < 0 || bestDistance > max(2, len(best)/3) {
+		return ""
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// binarySteps builds and runs cmd/<project>, for types with a main package
+func binarySteps(config Config) []string {
+	return []string{
+		"go mod tidy",
+		"go build -o bin/" + config.ProjectName + " ./cmd/" + config.ProjectName,
+		"./bin/" + config.ProjectName,
+	}
+}
+
+// loggingSection documents pkg/logger, for types that generate it
+func loggingSection() ReadmeSection {
+	return ReadmeSection{
+		Title: "Logging",
+		Body: "Uses Go's built-in slog package for structured logging.\n\nExample:\n" +
+			fenced("go", `log.InfoContext(ctx, "user logged in", "user_id", userID, "ip", ipAddress)`),
+	}
+}
+
+// fenced wraps code into a Markdown code block
+func fenced(lang, code string) string {
+	return "```" + lang + "\n" + code + "\n```"
+}
@@ -0,0 +1,573 @@
+package ginit
+
+// Шаблоны файлов типа worker
+
+const workerMainTemplate = `package main
+
+import (
+{{template "bootstrap.imports" .}}
+	"os/signal"
+	"syscall"
+
+	"{{.Module}}/internal/jobs"
+	"{{.Module}}/internal/worker"
+)
+
+func main() {
+{{template "bootstrap" .}}
+	log.InfoContext(ctx, "Starting {{.ProjectName}} worker...")
+{{- template "main.setup" .}}
+
+	// Источник задач: замените MemorySource на источник своей очереди
+	source := worker.NewMemorySource(cfg.Workers)
+
+	pool := worker.New(source, jobs.New(cfg, log).Handle, worker.Options{
+		Workers:      cfg.Workers,
+		MaxAttempts:  cfg.MaxAttempts,
+		Backoff:      cfg.RetryBackoff,
+		MaxBackoff:   cfg.MaxBackoff,
+		DrainTimeout: cfg.DrainTimeout,
+	}, log)
+
+	// Graceful drain: по сигналу пул перестает брать новые задачи и
+	// дожидается выполняющихся
+	runCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := pool.Run(runCtx); err != nil {
+		log.ErrorContext(ctx, "Worker failed", "error", err)
+		os.Exit(1)
+	}
+
+	log.InfoContext(ctx, "Worker stopped")
+}
+`
+
+const workerConfigTemplate = `package config
+
+import (
+	"sync"
+	"time"
+)
+
+type Config struct {
+	LogLevel     string
+	Workers      int
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	DrainTimeout time.Duration
+{{- template "config.fields" .}}
+}
+
+func defaults() *Config {
+	return &Config{
+		LogLevel:     "info",
+		Workers:      4,
+		MaxAttempts:  5,
+		RetryBackoff: time.Second,
+		MaxBackoff:   time.Minute,
+		DrainTimeout: 30 * time.Second,
+{{- template "config.defaults" .}}
+	}
+}
+
+{{template "config.load" .}}
+`
+
+const workerTemplate = `package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a unit of work received from a Source
+type Job struct {
+	ID      string
+	Payload []byte
+	// Attempt is the number of the current attempt, starting with 1
+	Attempt int
+}
+
+// Source delivers jobs to the pool. Implementations wrap a queue, such as
+// a message broker or a database table.
+type Source interface {
+	// Next blocks until a job is available or ctx is done
+	Next(ctx context.Context) (*Job, error)
+	// Ack confirms that job was processed
+	Ack(ctx context.Context, job *Job) error
+	// DeadLetter sets aside a job that failed every attempt with the
+	// error of the last one
+	DeadLetter(ctx context.Context, job *Job, err error) error
+}
+
+// Handler processes a job. A returned error makes the pool retry the job,
+// unless it is wrapped with Permanent.
+type Handler func(ctx context.Context, job *Job) error
+
+// Options configure a Pool. Zero values are replaced by defaults.
+type Options struct {
+	// Workers is the number of jobs processed concurrently
+	Workers int
+	// MaxAttempts is how often a job is tried before it is dead-lettered
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for each
+	// further one up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// DrainTimeout is how long running jobs may take after Run is
+	// stopped before they are cancelled
+	DrainTimeout time.Duration
+}
+
+// Pool takes jobs from a Source and runs them with a bounded number of
+// workers
+type Pool struct {
+	source  Source
+	handler Handler
+	opts    Options
+	log     *slog.Logger
+}
+
+func New(source Source, handler Handler, opts Options, log *slog.Logger) *Pool {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = opts.Backoff
+	}
+	if opts.DrainTimeout <= 0 {
+		opts.DrainTimeout = 30 * time.Second
+	}
+	return &Pool{source: source, handler: handler, opts: opts, log: log}
+}
+
+// Run processes jobs until ctx is done or the source fails. It then
+// stops taking jobs and waits up to DrainTimeout for the running ones;
+// jobs still running after that are cancelled and left unacknowledged.
+func (p *Pool) Run(ctx context.Context) error {
+	// Задачи не прерываются вместе с ctx, а дорабатывают до DrainTimeout
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	jobs := make(chan *Job)
+	var wg sync.WaitGroup
+	for i := 0; i < p.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				p.process(jobCtx, job)
+			}
+		}()
+	}
+
+	p.log.InfoContext(ctx, "Worker pool started", "workers", p.opts.Workers)
+	err := p.fetch(ctx, jobs)
+	close(jobs)
+
+	p.log.InfoContext(ctx, "Draining running jobs", "timeout", p.opts.DrainTimeout)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(p.opts.DrainTimeout):
+		p.log.WarnContext(ctx, "Drain timeout exceeded, cancelling running jobs")
+		cancel()
+		<-done
+	}
+	return err
+}
+
+// fetch hands jobs from the source to the workers until ctx is done. The
+// channel is unbuffered, so no more jobs are taken than workers are free.
+// A job received when ctx is done is not started and stays
+// unacknowledged for the queue to redeliver.
+func (p *Pool) fetch(ctx context.Context, jobs chan<- *Job) error {
+	for {
+		job, err := p.source.Next(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get next job: %w", err)
+		}
+
+		// Пока все воркеры заняты, сигнал должен прерывать ожидание,
+		// иначе отсчет DrainTimeout начнется только после освобождения воркера
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// process runs job until it succeeds, fails permanently or runs out of
+// attempts
+func (p *Pool) process(ctx context.Context, job *Job) {
+	log := p.log.With("job", job.ID)
+	for attempt := 1; ; attempt++ {
+		job.Attempt = attempt
+		err := p.handle(ctx, job)
+		if err == nil {
+			if err := p.source.Ack(ctx, job); err != nil {
+				log.ErrorContext(ctx, "Failed to ack job", "error", err)
+			}
+			return
+		}
+
+		// Отмененную задачу очередь доставит повторно
+		if ctx.Err() != nil {
+			log.WarnContext(ctx, "Job cancelled", "attempt", attempt, "error", err)
+			return
+		}
+
+		var permanent *permanentError
+		if attempt >= p.opts.MaxAttempts || errors.As(err, &permanent) {
+			log.ErrorContext(ctx, "Job failed, moving to dead letters", "attempt", attempt, "error", err)
+			if err := p.source.DeadLetter(ctx, job, err); err != nil {
+				log.ErrorContext(ctx, "Failed to dead-letter job", "error", err)
+			}
+			return
+		}
+
+		delay := p.backoff(attempt)
+		log.WarnContext(ctx, "Job failed, retrying", "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			log.WarnContext(ctx, "Job cancelled", "attempt", attempt, "error", err)
+			return
+		}
+	}
+}
+
+// handle calls the handler, turning a panic into an error
+func (p *Pool) handle(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return p.handler(ctx, job)
+}
+
+// backoff returns the delay after the failed attempt
+func (p *Pool) backoff(attempt int) time.Duration {
+	delay := p.opts.Backoff
+	for i := 1; i < attempt && delay < p.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.opts.MaxBackoff)
+}
+
+// Permanent wraps err so that the job is dead-lettered without retrying
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+`
+
+const workerMemoryTemplate = `package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// MemorySource is a Source keeping jobs in memory, for tests and local
+// runs. It records acknowledged and dead-lettered jobs.
+type MemorySource struct {
+	jobs chan *Job
+
+	mu    sync.Mutex
+	acked []*Job
+	dead  []FailedJob
+}
+
+// FailedJob is a dead-lettered job with the error of its last attempt
+type FailedJob struct {
+	Job *Job
+	Err error
+}
+
+// NewMemorySource returns a source holding up to size pending jobs
+func NewMemorySource(size int) *MemorySource {
+	return &MemorySource{jobs: make(chan *Job, size)}
+}
+
+// Push adds a job, blocking while the source is full
+func (s *MemorySource) Push(ctx context.Context, job *Job) error {
+	select {
+	case s.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *MemorySource) Next(ctx context.Context) (*Job, error) {
+	select {
+	case job := <-s.jobs:
+		return job, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *MemorySource) Ack(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acked = append(s.acked, job)
+	return nil
+}
+
+func (s *MemorySource) DeadLetter(ctx context.Context, job *Job, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dead = append(s.dead, FailedJob{Job: job, Err: err})
+	return nil
+}
+
+// Acked returns the acknowledged jobs
+func (s *MemorySource) Acked() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Job(nil), s.acked...)
+}
+
+// DeadLetters returns the dead-lettered jobs
+func (s *MemorySource) DeadLetters() []FailedJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]FailedJob(nil), s.dead...)
+}
+`
+
+const workerTestTemplate = `package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testOptions = Options{
+	Workers:      2,
+	MaxAttempts:  3,
+	Backoff:      time.Millisecond,
+	MaxBackoff:   5 * time.Millisecond,
+	DrainTimeout: time.Second,
+}
+
+// run processes jobs with the ids with handler and stops the pool once
+// all of them are acknowledged or dead-lettered
+func run(t *testing.T, handler Handler, ids ...string) *MemorySource {
+	t.Helper()
+
+	source := NewMemorySource(len(ids))
+	for _, id := range ids {
+		if err := source.Push(context.Background(), &Job{ID: id}); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool := New(source, handler, testOptions, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	errc := make(chan error, 1)
+	go func() { errc <- pool.Run(ctx) }()
+
+	deadline := time.After(5 * time.Second)
+	for len(source.Acked())+len(source.DeadLetters()) < len(ids) {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d jobs", len(ids))
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return source
+}
+
+func TestRetry(t *testing.T) {
+	handler := func(ctx context.Context, job *Job) error {
+		if job.Attempt < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	}
+
+	source := run(t, handler, "1")
+	acked := source.Acked()
+	if len(acked) != 1 || acked[0].Attempt != 3 {
+		t.Fatalf("acked = %v, want job 1 after 3 attempts", acked)
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	var calls atomic.Int32
+	handler := func(ctx context.Context, job *Job) error {
+		calls.Add(1)
+		if job.ID == "permanent" {
+			return Permanent(errors.New("invalid payload"))
+		}
+		return errors.New("always failing")
+	}
+
+	source := run(t, handler, "failing", "permanent")
+	if dead := source.DeadLetters(); len(dead) != 2 {
+		t.Fatalf("dead letters = %v, want 2", dead)
+	}
+	// 3 попытки у обычной ошибки и одна у постоянной
+	if n := calls.Load(); n != 4 {
+		t.Errorf("handler called %d times, want 4", n)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	handler := func(ctx context.Context, job *Job) error {
+		panic("boom")
+	}
+
+	source := run(t, handler, "1")
+	if dead := source.DeadLetters(); len(dead) != 1 {
+		t.Fatalf("dead letters = %v, want the panicking job", dead)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	source := NewMemorySource(1)
+	if err := source.Push(context.Background(), &Job{ID: "slow"}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	started := make(chan struct{})
+	handler := func(ctx context.Context, job *Job) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := New(source, handler, testOptions, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	errc := make(chan error, 1)
+	go func() { errc <- pool.Run(ctx) }()
+
+	<-started
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if acked := source.Acked(); len(acked) != 1 {
+		t.Errorf("acked = %v, want the running job to finish", acked)
+	}
+}
+
+func TestDrainBusy(t *testing.T) {
+	source := NewMemorySource(3)
+	for _, id := range []string{"running", "waiting", "queued"} {
+		if err := source.Push(context.Background(), &Job{ID: id}); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+	}
+
+	var calls atomic.Int32
+	started := make(chan struct{}, 3)
+	handler := func(ctx context.Context, job *Job) error {
+		calls.Add(1)
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	opts := testOptions
+	opts.Workers = 1
+	opts.DrainTimeout = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := New(source, handler, opts, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	errc := make(chan error, 1)
+	go func() { errc <- pool.Run(ctx) }()
+
+	<-started
+	cancel()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after DrainTimeout")
+	}
+	// Задачи, полученные после сигнала, не запускаются
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler called %d times, want 1", n)
+	}
+	if acked := source.Acked(); len(acked) != 0 {
+		t.Errorf("acked = %v, want none", acked)
+	}
+}
+`
+
+const workerJobsTemplate = `package jobs
+
+import (
+	"context"
+	"log/slog"
+
+	"{{.Module}}/internal/config"
+	"{{.Module}}/internal/worker"
+)
+
+type Handler struct {
+	config *config.Config
+	log    *slog.Logger
+}
+
+func New(cfg *config.Config, log *slog.Logger) *Handler {
+	return &Handler{
+		config: cfg,
+		log:    log,
+	}
+}
+
+// Handle processes a single job. Returned errors are retried, wrap them
+// with worker.Permanent for jobs that can never succeed.
+func (h *Handler) Handle(ctx context.Context, job *worker.Job) error {
+	h.log.InfoContext(ctx, "Processing job", "job", job.ID, "attempt", job.Attempt, "size", len(job.Payload))
+	return nil
+}
+`